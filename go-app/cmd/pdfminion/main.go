@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+	"pdfminion/internal/config"
+)
+
+// appVersion is set by the build process (Makefile), e.g. -ldflags "-X main.appVersion=0.3.2"
+var appVersion = "dev"
+
+func main() {
+	os.Exit(config.Execute(appVersion))
+}
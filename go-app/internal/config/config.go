@@ -58,16 +58,15 @@ func ConfigureApplication(verbose bool, flagChecker FlagChecker) (domain.MinionC
 	if flagChecker.HasBeenProvided("config") {
 		configFile := viper.GetString("config")
 		if configFile != "" {
-			if fileConfig, err := loadConfigFile(configFile, verbose); err == nil {
-				if verbose {
-					fmt.Printf("Merging configuration from file: %s\n", configFile)
-				}
-				err := minionConfig.MergeWith(fileConfig)
-				if err != nil {
-					log.Warn().Err(err).Msg("Failed to merge file configuration")
-				}
-			} else {
-				log.Warn().Err(err).Str("file", configFile).Msg("Failed to load config file")
+			fileConfig, err := loadConfigFile(configFile, verbose)
+			if err != nil {
+				return minionConfig, fmt.Errorf("%w: error loading config file %s: %v", domain.ErrInvalidConfig, configFile, err)
+			}
+			if verbose {
+				fmt.Printf("Merging configuration from file: %s\n", configFile)
+			}
+			if err := minionConfig.MergeWith(fileConfig); err != nil {
+				return minionConfig, fmt.Errorf("%w: error merging config file %s: %v", domain.ErrInvalidConfig, configFile, err)
 			}
 		}
 	}
@@ -77,9 +76,8 @@ func ConfigureApplication(verbose bool, flagChecker FlagChecker) (domain.MinionC
 	if verbose {
 		fmt.Println("Merging flag configuration")
 	}
-	err := minionConfig.MergeWith(flagConfig)
-	if err != nil {
-		log.Warn().Err(err).Msg("Failed to merge flag configuration")
+	if err := minionConfig.MergeWith(flagConfig); err != nil {
+		return minionConfig, fmt.Errorf("%w: error merging flag configuration: %v", domain.ErrInvalidConfig, err)
 	}
 
 	if verbose {
@@ -116,20 +114,73 @@ func loadFlagConfig(flagChecker FlagChecker) domain.MinionConfig {
 	}
 
 	// to avoid the too-long-function linter error, we split the flag loading
-	loadFlagProcessingConfig(fconfig, flagChecker)
+	loadFlagProcessingConfig(&fconfig, flagChecker)
+
+	loadFlagTextOnPageConfig(&fconfig, flagChecker)
 
-	loadFlagTextOnPageConfig(fconfig, flagChecker)
+	loadFlagPersonalTouchConfig(&fconfig, flagChecker)
 
+	loadFlagFooterLayoutConfig(&fconfig, flagChecker)
+
+	return fconfig
+
+}
+
+func loadFlagPersonalTouchConfig(fconfig *domain.MinionConfig, flagChecker FlagChecker) {
 	if flagChecker.HasBeenProvided("personal") {
 		fconfig.PersonalTouch = viper.GetBool("personal")
 		fconfig.SetFields["personal"] = true
 	}
+	if flagChecker.HasBeenProvided("personal-image") {
+		fconfig.PersonalTouchImage = viper.GetString("personal-image")
+		fconfig.SetFields["personalimage"] = true
+	}
+	if flagChecker.HasBeenProvided("personal-density") {
+		fconfig.PersonalTouchDensity = viper.GetInt("personal-density")
+		fconfig.SetFields["personaldensity"] = true
+	}
+	if flagChecker.HasBeenProvided("personal-seed") {
+		fconfig.PersonalTouchSeed = viper.GetInt64("personal-seed")
+		fconfig.SetFields["personalseed"] = true
+	}
+}
 
-	return fconfig
-
+func loadFlagFooterLayoutConfig(fconfig *domain.MinionConfig, flagChecker FlagChecker) {
+	if flagChecker.HasBeenProvided("footer-position") {
+		fconfig.FooterPosition = viper.GetString("footer-position")
+		fconfig.SetFields["footerposition"] = true
+	}
+	if flagChecker.HasBeenProvided("footer-offset-x") {
+		fconfig.FooterOffsetX = viper.GetInt("footer-offset-x")
+		fconfig.SetFields["footeroffsetx"] = true
+	}
+	if flagChecker.HasBeenProvided("footer-offset-y") {
+		fconfig.FooterOffsetY = viper.GetInt("footer-offset-y")
+		fconfig.SetFields["footeroffsety"] = true
+	}
+	if flagChecker.HasBeenProvided("footer-font") {
+		fconfig.FooterFont = viper.GetString("footer-font")
+		fconfig.SetFields["footerfont"] = true
+	}
+	if flagChecker.HasBeenProvided("footer-font-size") {
+		fconfig.FooterFontSize = viper.GetInt("footer-font-size")
+		fconfig.SetFields["footerfontsize"] = true
+	}
+	if flagChecker.HasBeenProvided("footer-color") {
+		fconfig.FooterColor = viper.GetString("footer-color")
+		fconfig.SetFields["footercolor"] = true
+	}
+	if flagChecker.HasBeenProvided("footer-opacity") {
+		fconfig.FooterOpacity = viper.GetFloat64("footer-opacity")
+		fconfig.SetFields["footeropacity"] = true
+	}
+	if flagChecker.HasBeenProvided("font-file") {
+		fconfig.FontFile = viper.GetString("font-file")
+		fconfig.SetFields["fontfile"] = true
+	}
 }
 
-func loadFlagTextOnPageConfig(fconfig domain.MinionConfig, flagChecker FlagChecker) {
+func loadFlagTextOnPageConfig(fconfig *domain.MinionConfig, flagChecker FlagChecker) {
 	if flagChecker.HasBeenProvided("language") {
 		fconfig.Language = domain.ParseLanguageCode(viper.GetString("language"))
 		fconfig.SetFields["language"] = true
@@ -138,6 +189,58 @@ func loadFlagTextOnPageConfig(fconfig domain.MinionConfig, flagChecker FlagCheck
 		fconfig.RunningHeader = viper.GetString("running-header")
 		fconfig.SetFields["runningheader"] = true
 	}
+	if flagChecker.HasBeenProvided("running-header-even") {
+		fconfig.RunningHeaderEven = viper.GetString("running-header-even")
+		fconfig.SetFields["runningheadereven"] = true
+	}
+	if flagChecker.HasBeenProvided("running-header-odd") {
+		fconfig.RunningHeaderOdd = viper.GetString("running-header-odd")
+		fconfig.SetFields["runningheaderodd"] = true
+	}
+	if flagChecker.HasBeenProvided("footer-template") {
+		fconfig.FooterTemplate = viper.GetString("footer-template")
+		fconfig.SetFields["footertemplate"] = true
+	}
+	if flagChecker.HasBeenProvided("footer-template-even") {
+		fconfig.FooterTemplateEven = viper.GetString("footer-template-even")
+		fconfig.SetFields["footertemplateeven"] = true
+	}
+	if flagChecker.HasBeenProvided("footer-template-odd") {
+		fconfig.FooterTemplateOdd = viper.GetString("footer-template-odd")
+		fconfig.SetFields["footertemplateodd"] = true
+	}
+	if flagChecker.HasBeenProvided("course") {
+		fconfig.Course = viper.GetString("course")
+		fconfig.SetFields["course"] = true
+	}
+	if flagChecker.HasBeenProvided("chapter-number-style") {
+		fconfig.ChapterNumberStyle = viper.GetString("chapter-number-style")
+		fconfig.SetFields["chapternumberstyle"] = true
+	}
+	if flagChecker.HasBeenProvided("page-number-style") {
+		fconfig.PageNumberStyle = viper.GetString("page-number-style")
+		fconfig.SetFields["pagenumberstyle"] = true
+	}
+	if flagChecker.HasBeenProvided("page-numbering") {
+		fconfig.PageNumbering = viper.GetString("page-numbering")
+		fconfig.SetFields["pagenumbering"] = true
+	}
+	if flagChecker.HasBeenProvided("chapter-page-separator") {
+		fconfig.ChapterPageSeparator = viper.GetString("chapter-page-separator")
+		fconfig.SetFields["chapterpageseparator"] = true
+	}
+	if flagChecker.HasBeenProvided("first-chapter") {
+		fconfig.FirstChapter = viper.GetInt("first-chapter")
+		fconfig.SetFields["firstchapter"] = true
+	}
+	if flagChecker.HasBeenProvided("first-page") {
+		fconfig.FirstPage = viper.GetInt("first-page")
+		fconfig.SetFields["firstpage"] = true
+	}
+	if flagChecker.HasBeenProvided("continue-from") {
+		fconfig.ContinueFrom = viper.GetString("continue-from")
+		fconfig.SetFields["continuefrom"] = true
+	}
 	if flagChecker.HasBeenProvided("chapter-prefix") {
 		fconfig.ChapterPrefix = viper.GetString("chapter-prefix")
 		fconfig.SetFields["chapterprefix"] = true
@@ -154,14 +257,17 @@ func loadFlagTextOnPageConfig(fconfig domain.MinionConfig, flagChecker FlagCheck
 		fconfig.PageCountPrefix = viper.GetString("page-count-prefix")
 		fconfig.SetFields["pagecountprefix"] = true
 	}
+	if flagChecker.HasBeenProvided("page-count-scope") {
+		fconfig.PageCountScope = viper.GetString("page-count-scope")
+		fconfig.SetFields["pagecountscope"] = true
+	}
 	if flagChecker.HasBeenProvided("blank-page-text") {
 		fconfig.BlankPageText = viper.GetString("blank-page-text")
 		fconfig.SetFields["blankpagetext"] = true
 	}
-	// Add the TOC flag
-	if flagChecker.HasBeenProvided("toc") {
-		// We'll need to add this field to MinionConfig struct
-		fconfig.SetFields["toc"] = true
+	if flagChecker.HasBeenProvided("toc-title") {
+		fconfig.TOCTitle = viper.GetString("toc-title")
+		fconfig.SetFields["toctitle"] = true
 	}
 }
 
@@ -193,6 +299,51 @@ func loadConfigFile(configFile string, verbose bool) (domain.MinionConfig, error
 		config.SetFields["sourcedir"] = true
 	}
 	
+	if v.IsSet("recursive") {
+		config.Recursive = v.GetBool("recursive")
+		config.SetFields["recursive"] = true
+	}
+	
+	if v.IsSet("include") {
+		config.Include = v.GetStringSlice("include")
+		config.SetFields["include"] = true
+	}
+	
+	if v.IsSet("exclude") {
+		config.Exclude = v.GetStringSlice("exclude")
+		config.SetFields["exclude"] = true
+	}
+	
+	if v.IsSet("front-matter") {
+		config.FrontMatter = v.GetStringSlice("front-matter")
+		config.SetFields["frontmatter"] = true
+	}
+	
+	if v.IsSet("back-matter") {
+		config.BackMatter = v.GetStringSlice("back-matter")
+		config.SetFields["backmatter"] = true
+	}
+	
+	if v.IsSet("matter-numbering") {
+		config.MatterNumbering = v.GetString("matter-numbering")
+		config.SetFields["matternumbering"] = true
+	}
+	
+	if v.IsSet("order") {
+		config.Order = v.GetString("order")
+		config.SetFields["order"] = true
+	}
+	
+	if v.IsSet("manifest") {
+		config.Manifest = v.GetString("manifest")
+		config.SetFields["manifest"] = true
+	}
+	
+	if v.IsSet("handout") {
+		config.Handout = v.GetString("handout")
+		config.SetFields["handout"] = true
+	}
+	
 	if v.IsSet("target") {
 		config.TargetDir = v.GetString("target")
 		config.SetFields["targetdir"] = true
@@ -203,11 +354,31 @@ func loadConfigFile(configFile string, verbose bool) (domain.MinionConfig, error
 		config.SetFields["force"] = true
 	}
 	
+	if v.IsSet("strict") {
+		config.Strict = v.GetBool("strict")
+		config.SetFields["strict"] = true
+	}
+	
+	if v.IsSet("jobs") {
+		config.Jobs = v.GetInt("jobs")
+		config.SetFields["jobs"] = true
+	}
+	
 	if v.IsSet("evenify") {
 		config.Evenify = v.GetBool("evenify")
 		config.SetFields["evenify"] = true
 	}
 	
+	if v.IsSet("evenify-policy") {
+		config.EvenifyPolicy = v.GetString("evenify-policy")
+		config.SetFields["evenifypolicy"] = true
+	}
+	
+	if v.IsSet("evenify-scope") {
+		config.EvenifyScope = v.GetString("evenify-scope")
+		config.SetFields["evenifyscope"] = true
+	}
+	
 	if v.IsSet("verbose") {
 		config.Verbose = v.GetBool("verbose")
 		config.SetFields["verbose"] = true
@@ -218,6 +389,71 @@ func loadConfigFile(configFile string, verbose bool) (domain.MinionConfig, error
 		config.SetFields["runningheader"] = true
 	}
 	
+	if v.IsSet("running-header-even") {
+		config.RunningHeaderEven = v.GetString("running-header-even")
+		config.SetFields["runningheadereven"] = true
+	}
+	
+	if v.IsSet("running-header-odd") {
+		config.RunningHeaderOdd = v.GetString("running-header-odd")
+		config.SetFields["runningheaderodd"] = true
+	}
+	
+	if v.IsSet("footer-template") {
+		config.FooterTemplate = v.GetString("footer-template")
+		config.SetFields["footertemplate"] = true
+	}
+	
+	if v.IsSet("footer-template-even") {
+		config.FooterTemplateEven = v.GetString("footer-template-even")
+		config.SetFields["footertemplateeven"] = true
+	}
+	
+	if v.IsSet("footer-template-odd") {
+		config.FooterTemplateOdd = v.GetString("footer-template-odd")
+		config.SetFields["footertemplateodd"] = true
+	}
+	
+	if v.IsSet("course") {
+		config.Course = v.GetString("course")
+		config.SetFields["course"] = true
+	}
+	
+	if v.IsSet("chapter-number-style") {
+		config.ChapterNumberStyle = v.GetString("chapter-number-style")
+		config.SetFields["chapternumberstyle"] = true
+	}
+	
+	if v.IsSet("page-number-style") {
+		config.PageNumberStyle = v.GetString("page-number-style")
+		config.SetFields["pagenumberstyle"] = true
+	}
+	
+	if v.IsSet("page-numbering") {
+		config.PageNumbering = v.GetString("page-numbering")
+		config.SetFields["pagenumbering"] = true
+	}
+	
+	if v.IsSet("chapter-page-separator") {
+		config.ChapterPageSeparator = v.GetString("chapter-page-separator")
+		config.SetFields["chapterpageseparator"] = true
+	}
+	
+	if v.IsSet("first-chapter") {
+		config.FirstChapter = v.GetInt("first-chapter")
+		config.SetFields["firstchapter"] = true
+	}
+	
+	if v.IsSet("first-page") {
+		config.FirstPage = v.GetInt("first-page")
+		config.SetFields["firstpage"] = true
+	}
+	
+	if v.IsSet("continue-from") {
+		config.ContinueFrom = v.GetString("continue-from")
+		config.SetFields["continuefrom"] = true
+	}
+	
 	if v.IsSet("chapter-prefix") {
 		config.ChapterPrefix = v.GetString("chapter-prefix")
 		config.SetFields["chapterprefix"] = true
@@ -233,6 +469,11 @@ func loadConfigFile(configFile string, verbose bool) (domain.MinionConfig, error
 		config.SetFields["pagecountprefix"] = true
 	}
 	
+	if v.IsSet("page-count-scope") {
+		config.PageCountScope = v.GetString("page-count-scope")
+		config.SetFields["pagecountscope"] = true
+	}
+	
 	if v.IsSet("blank-page-text") {
 		config.BlankPageText = v.GetString("blank-page-text")
 		config.SetFields["blankpagetext"] = true
@@ -243,6 +484,46 @@ func loadConfigFile(configFile string, verbose bool) (domain.MinionConfig, error
 		config.SetFields["separator"] = true
 	}
 	
+	if v.IsSet("footer-position") {
+		config.FooterPosition = v.GetString("footer-position")
+		config.SetFields["footerposition"] = true
+	}
+	
+	if v.IsSet("footer-offset-x") {
+		config.FooterOffsetX = v.GetInt("footer-offset-x")
+		config.SetFields["footeroffsetx"] = true
+	}
+	
+	if v.IsSet("footer-offset-y") {
+		config.FooterOffsetY = v.GetInt("footer-offset-y")
+		config.SetFields["footeroffsety"] = true
+	}
+	
+	if v.IsSet("footer-font") {
+		config.FooterFont = v.GetString("footer-font")
+		config.SetFields["footerfont"] = true
+	}
+	
+	if v.IsSet("footer-font-size") {
+		config.FooterFontSize = v.GetInt("footer-font-size")
+		config.SetFields["footerfontsize"] = true
+	}
+	
+	if v.IsSet("footer-color") {
+		config.FooterColor = v.GetString("footer-color")
+		config.SetFields["footercolor"] = true
+	}
+	
+	if v.IsSet("footer-opacity") {
+		config.FooterOpacity = v.GetFloat64("footer-opacity")
+		config.SetFields["footeropacity"] = true
+	}
+	
+	if v.IsSet("font-file") {
+		config.FontFile = v.GetString("font-file")
+		config.SetFields["fontfile"] = true
+	}
+	
 	if v.IsSet("merge") {
 		config.Merge = true
 		config.MergeFileName = v.GetString("merge")
@@ -254,15 +535,40 @@ func loadConfigFile(configFile string, verbose bool) (domain.MinionConfig, error
 		config.SetFields["personal"] = true
 	}
 	
+	if v.IsSet("personal-image") {
+		config.PersonalTouchImage = v.GetString("personal-image")
+		config.SetFields["personalimage"] = true
+	}
+	
+	if v.IsSet("personal-density") {
+		config.PersonalTouchDensity = v.GetInt("personal-density")
+		config.SetFields["personaldensity"] = true
+	}
+	
+	if v.IsSet("personal-seed") {
+		config.PersonalTouchSeed = v.GetInt64("personal-seed")
+		config.SetFields["personalseed"] = true
+	}
+	
 	if v.IsSet("toc") {
 		config.TOC = v.GetBool("toc")
 		config.SetFields["toc"] = true
 	}
+
+	if v.IsSet("toc-title") {
+		config.TOCTitle = v.GetString("toc-title")
+		config.SetFields["toctitle"] = true
+	}
+	
+	if v.IsSet("report") {
+		config.Report = v.GetString("report")
+		config.SetFields["report"] = true
+	}
 	
 	return config, nil
 }
 
-func loadFlagProcessingConfig(fconfig domain.MinionConfig, flagChecker FlagChecker) {
+func loadFlagProcessingConfig(fconfig *domain.MinionConfig, flagChecker FlagChecker) {
 	if flagChecker.HasBeenProvided("verbose") {
 		fconfig.Verbose = viper.GetBool("verbose")
 		fconfig.SetFields["verbose"] = true
@@ -271,6 +577,42 @@ func loadFlagProcessingConfig(fconfig domain.MinionConfig, flagChecker FlagCheck
 		fconfig.SourceDir = viper.GetString("source")
 		fconfig.SetFields["sourcedir"] = true
 	}
+	if flagChecker.HasBeenProvided("recursive") {
+		fconfig.Recursive = viper.GetBool("recursive")
+		fconfig.SetFields["recursive"] = true
+	}
+	if flagChecker.HasBeenProvided("include") {
+		fconfig.Include = viper.GetStringSlice("include")
+		fconfig.SetFields["include"] = true
+	}
+	if flagChecker.HasBeenProvided("exclude") {
+		fconfig.Exclude = viper.GetStringSlice("exclude")
+		fconfig.SetFields["exclude"] = true
+	}
+	if flagChecker.HasBeenProvided("front-matter") {
+		fconfig.FrontMatter = viper.GetStringSlice("front-matter")
+		fconfig.SetFields["frontmatter"] = true
+	}
+	if flagChecker.HasBeenProvided("back-matter") {
+		fconfig.BackMatter = viper.GetStringSlice("back-matter")
+		fconfig.SetFields["backmatter"] = true
+	}
+	if flagChecker.HasBeenProvided("matter-numbering") {
+		fconfig.MatterNumbering = viper.GetString("matter-numbering")
+		fconfig.SetFields["matternumbering"] = true
+	}
+	if flagChecker.HasBeenProvided("order") {
+		fconfig.Order = viper.GetString("order")
+		fconfig.SetFields["order"] = true
+	}
+	if flagChecker.HasBeenProvided("manifest") {
+		fconfig.Manifest = viper.GetString("manifest")
+		fconfig.SetFields["manifest"] = true
+	}
+	if flagChecker.HasBeenProvided("handout") {
+		fconfig.Handout = viper.GetString("handout")
+		fconfig.SetFields["handout"] = true
+	}
 	if flagChecker.HasBeenProvided("target") {
 		fconfig.TargetDir = viper.GetString("target")
 		fconfig.SetFields["targetdir"] = true
@@ -279,10 +621,30 @@ func loadFlagProcessingConfig(fconfig domain.MinionConfig, flagChecker FlagCheck
 		fconfig.Force = viper.GetBool("force")
 		fconfig.SetFields["force"] = true
 	}
+	if flagChecker.HasBeenProvided("strict") {
+		fconfig.Strict = viper.GetBool("strict")
+		fconfig.SetFields["strict"] = true
+	}
+	if flagChecker.HasBeenProvided("dry-run") {
+		fconfig.DryRun = viper.GetBool("dry-run")
+		fconfig.SetFields["dryrun"] = true
+	}
+	if flagChecker.HasBeenProvided("jobs") {
+		fconfig.Jobs = viper.GetInt("jobs")
+		fconfig.SetFields["jobs"] = true
+	}
 	if flagChecker.HasBeenProvided("evenify") {
 		fconfig.Evenify = viper.GetBool("evenify")
 		fconfig.SetFields["evenify"] = true
 	}
+	if flagChecker.HasBeenProvided("evenify-policy") {
+		fconfig.EvenifyPolicy = viper.GetString("evenify-policy")
+		fconfig.SetFields["evenifypolicy"] = true
+	}
+	if flagChecker.HasBeenProvided("evenify-scope") {
+		fconfig.EvenifyScope = viper.GetString("evenify-scope")
+		fconfig.SetFields["evenifyscope"] = true
+	}
 	if flagChecker.HasBeenProvided("merge") {
 		fconfig.Merge = true
 		fconfig.MergeFileName = viper.GetString("merge")
@@ -292,4 +654,8 @@ func loadFlagProcessingConfig(fconfig domain.MinionConfig, flagChecker FlagCheck
 		fconfig.TOC = viper.GetBool("toc")
 		fconfig.SetFields["toc"] = true
 	}
+	if flagChecker.HasBeenProvided("report") {
+		fconfig.Report = viper.GetString("report")
+		fconfig.SetFields["report"] = true
+	}
 }
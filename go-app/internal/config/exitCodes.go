@@ -0,0 +1,61 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"pdfminion/internal/domain"
+	"syscall"
+)
+
+// Process exit codes, so that scripts can tell the kinds of failure apart.
+// They are documented in PRD.md, section "Exit Codes".
+const (
+	ExitOK              = 0
+	ExitGeneralError    = 1
+	ExitInvalidConfig   = 2
+	ExitNoPDFsFound     = 3
+	ExitTargetNotEmpty  = 4
+	ExitInvalidPDF      = 5
+	ExitWatermarkFailed = 6
+	// ExitInterrupted follows the shell convention of 128 + SIGINT
+	ExitInterrupted = 130
+)
+
+// ExitCodeFor maps an error returned by a command to its process exit code
+func ExitCodeFor(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.Is(err, domain.ErrInvalidConfig):
+		return ExitInvalidConfig
+	case errors.Is(err, domain.ErrNoPDFsFound):
+		return ExitNoPDFsFound
+	case errors.Is(err, domain.ErrTargetNotEmpty):
+		return ExitTargetNotEmpty
+	case errors.Is(err, domain.ErrInvalidPDF):
+		return ExitInvalidPDF
+	case errors.Is(err, domain.ErrWatermarkFailed):
+		return ExitWatermarkFailed
+	default:
+		return ExitGeneralError
+	}
+}
+
+// Execute runs the root command and returns the process exit code.
+// The first interrupt (Ctrl-C) stops processing between files, a second one terminates immediately.
+func Execute(appVersion string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		// restore the default behavior for the next interrupt
+		stop()
+	}()
+
+	return ExitCodeFor(SetupApplication(appVersion).ExecuteContext(ctx))
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, ExitOK},
+		{errors.New("something else"), ExitGeneralError},
+		{fmt.Errorf("%w: unknown scope", domain.ErrInvalidConfig), ExitInvalidConfig},
+		{fmt.Errorf("error collecting candidate PDFs: %w", fmt.Errorf("%w in _pdfs", domain.ErrNoPDFsFound)), ExitNoPDFsFound},
+		{fmt.Errorf("error during copy: %w", domain.ErrTargetNotEmpty), ExitTargetNotEmpty},
+		{fmt.Errorf("%w: none is valid", domain.ErrInvalidPDF), ExitInvalidPDF},
+		{fmt.Errorf("error adding page numbers: %w", domain.ErrWatermarkFailed), ExitWatermarkFailed},
+		{fmt.Errorf("processing interrupted: %w", context.Canceled), ExitInterrupted},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ExitCodeFor(tt.err), "error %v", tt.err)
+	}
+}
+
+// configFlagChecker reports only the config flag as provided
+type configFlagChecker struct{}
+
+func (configFlagChecker) HasBeenProvided(flagName string) bool {
+	return flagName == "config"
+}
+
+func TestExitCodeForInvalidConfigFile(t *testing.T) {
+	malformed := filepath.Join(t.TempDir(), "malformed.yaml")
+	assert.NoError(t, os.WriteFile(malformed, []byte("language: [EN\n"), 0644))
+
+	tests := map[string]string{
+		"missing file":   filepath.Join(t.TempDir(), "missing.yaml"),
+		"malformed file": malformed,
+	}
+
+	t.Cleanup(func() { viper.Set("config", "") })
+	for name, configFile := range tests {
+		viper.Set("config", configFile)
+		_, err := ConfigureApplication(false, configFlagChecker{})
+		assert.ErrorIs(t, err, domain.ErrInvalidConfig, name)
+		assert.Equal(t, ExitInvalidConfig, ExitCodeFor(err), name)
+	}
+}
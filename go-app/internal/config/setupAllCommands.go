@@ -2,7 +2,6 @@ package config
 
 import (
 	"fmt"
-	"os"
 	"pdfminion/internal/pdf"
 
 	"github.com/rs/zerolog/log"
@@ -18,6 +17,11 @@ var (
 		Use:   "pdfminion",
 		Short: "PDFMinion adds page numbers to PDF files with custom options",
 		Long:  "PDFMinion is a CLI tool to add page numbers to existing PDF files with customizable options like chapter numbers, running headers, and more",
+		// Flags are only available after cobra has parsed the command line,
+		// therefore the configuration is determined right before a command runs.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return configureFromFlags(cmd.Root())
+		},
 		// When no subcommand is provided, process PDFs with the given flags
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runPDFProcessing(cmd, args)
@@ -41,30 +45,34 @@ func SetupApplication(appVersion string) *cobra.Command {
 	// --language determines several default settings
 	setupFlags()
 
+	// Setup commands for the root CLI application
+	setupCommands()
+
+	return rootCmd
+}
+
+// configureFromFlags determines the active configuration
+// using our layered approach, see ADR-0008
+func configureFromFlags(root *cobra.Command) error {
 	// check if verbose is set
 	verbose = viper.GetBool("verbose")
 	log.Debug().Bool("verbose", verbose).Msg("flag ")
 
 	if verbose {
-		fmt.Printf("verbose mode requested.")
+		fmt.Println("verbose mode requested.")
 	}
-	// Setup commands for the root CLI application
-	setupCommands()
 
 	// Create a flag checker for configuration
-	flagChecker := NewCobraFlagChecker(rootCmd)
+	flagChecker := NewCobraFlagChecker(root)
 
-	// Now that commands are set up, we can configure the application
-	// using our layered approach, see ADR-0008
 	var err error
 	ActiveMinionConfig, err = ConfigureApplication(verbose, flagChecker)
 	if err != nil {
 		log.Error().Err(err).Msg("Error loading configuration")
-		os.Exit(1)
+		return err
 	}
 	log.Debug().Interface("configuration:", ActiveMinionConfig).Msg("Configuration completed ")
-
-	return rootCmd
+	return nil
 }
 
 func setupFlags() {
@@ -72,21 +80,65 @@ func setupFlags() {
 	rootCmd.PersistentFlags().StringP("language", "l", "", "Override system language")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Give more detailed output during processing")
 	rootCmd.PersistentFlags().StringP("config", "c", "", "Path to configuration file")
+	// the handout is shown by the settings command, too
+	rootCmd.PersistentFlags().String("handout", "", "Handout manifest listing the chapters (default: pdfminion.handout.yaml in source directory, if present)")
 
 	// Local flags (only for PDF processing)
 	rootCmd.Flags().StringP("source", "s", domain.DefaultSourceDir, "Source directory for PDF files")
+	rootCmd.Flags().Bool("recursive", domain.DefaultRecursive, "Collect PDF files from sub-folders of the source directory, too")
+	rootCmd.Flags().StringSlice("include", nil, "Only process files matching these glob patterns, e.g. --include 'ch*.pdf'")
+	rootCmd.Flags().StringSlice("exclude", nil, "Ignore files and folders matching these glob patterns, e.g. --exclude 'draft*'")
+	rootCmd.Flags().StringSlice("front-matter", nil, "Files matching these glob patterns are front matter without chapter number, e.g. --front-matter 'cover*.pdf'")
+	rootCmd.Flags().StringSlice("back-matter", nil, "Files matching these glob patterns are back matter without chapter number")
+	rootCmd.Flags().String("matter-numbering", domain.DefaultMatterNumbering, "Page numbers of front and back matter: none or roman")
+	rootCmd.Flags().String("order", domain.DefaultOrder, "Chapter order: natural, lexical, mtime or manifest")
+	rootCmd.Flags().String("manifest", "", "File listing the chapters in order, for --order manifest (default: chapters.txt in source directory)")
 	rootCmd.Flags().StringP("target", "t", domain.DefaultTargetDir, "Target directory for processed files")
 	rootCmd.Flags().BoolP("force", "f", false, "Force overwrite of target directory")
+	rootCmd.Flags().Bool("strict", false, "Abort without writing any output if a file is not a valid PDF")
+	rootCmd.Flags().Bool("dry-run", false, "Show chapters, page ranges and footers without writing any file")
+	rootCmd.Flags().IntP("jobs", "j", domain.DefaultJobs, "Number of files processed concurrently (0: one per CPU)")
 	rootCmd.Flags().BoolP("evenify", "e", true, "Ensure even page count in output")
-	rootCmd.Flags().StringP("running-header", "r", "", "Text for running header")
+	rootCmd.Flags().String("evenify-policy", domain.DefaultEvenifyPolicy, "Blank pages added by evenify: even, multiple-of-4, start-right or warn")
+	rootCmd.Flags().String("evenify-scope", domain.DefaultEvenifyScope, "Where evenify adds blank pages: chapter (every file) or merged (merged file only)")
+	rootCmd.Flags().StringP("running-header", "r", "", "Text for running header, may contain placeholders like {chapterTitle}")
+	rootCmd.Flags().String("running-header-even", "", "Running header for even pages, replaces --running-header")
+	rootCmd.Flags().String("running-header-odd", "", "Running header for odd pages, replaces --running-header")
+	rootCmd.Flags().String("footer-template", "", "Footer template, e.g. \"{chapterTitle} - {page}/{total}\" (default: built from the prefixes)")
+	rootCmd.Flags().String("footer-template-even", "", "Footer template for even pages, replaces --footer-template")
+	rootCmd.Flags().String("footer-template-odd", "", "Footer template for odd pages, replaces --footer-template")
+	rootCmd.Flags().String("course", "", "Course name for the {course} placeholder")
+	rootCmd.Flags().String("chapter-number-style", domain.DefaultChapterNumberStyle, "Chapter numbers: arabic, lower-roman, upper-roman, lower-alpha or upper-alpha")
+	rootCmd.Flags().String("page-number-style", domain.DefaultPageNumberStyle, "Page numbers: arabic, lower-roman, upper-roman, lower-alpha or upper-alpha")
+	rootCmd.Flags().String("page-numbering", domain.DefaultPageNumbering, "Page numbering: continuous, or chapter to restart in every chapter (e.g. 3.7)")
+	rootCmd.Flags().String("chapter-page-separator", domain.DefaultChapterPageSeparator, "Separator between chapter and page number with --page-numbering chapter")
+	rootCmd.Flags().Int("first-chapter", domain.DefaultFirstChapter, "Number of the first chapter")
+	rootCmd.Flags().Int("first-page", domain.DefaultFirstPage, "Number of the first page")
+	rootCmd.Flags().String("continue-from", "", "Report (.json or .csv) of a previous run, chapters and pages are numbered after its last chapter and page")
 	rootCmd.Flags().String("chapter-prefix", domain.DefaultChapterPrefix, "Prefix for chapter numbers")
 	rootCmd.Flags().StringP("page-prefix", "p", domain.DefaultPageNrPrefix, "Prefix for page numbers")
 	rootCmd.Flags().StringP("blank-page-text", "b", domain.DefaultBlankPageText, "Text for blank pages")
 	rootCmd.Flags().Bool("personal", false, "Adds a personal touch (aka logo) to random pages")
-	rootCmd.Flags().String("merge", "merged.pdf", "--merge=filename, merge generated files into <filename>")
+	rootCmd.Flags().String("personal-image", "", "Image file for the personal touch (default: PDFminion mascot)")
+	rootCmd.Flags().Int("personal-density", domain.DefaultPersonalTouchDensity, "Pages per hundred with a personal touch")
+	rootCmd.Flags().Int64("personal-seed", domain.DefaultPersonalTouchSeed, "Seed to select the pages with a personal touch")
+	rootCmd.Flags().String("merge", domain.DefaultMergeFileName, "--merge=filename, merge generated files into <filename>")
+	// allow --merge without filename, then the default name is used
+	rootCmd.Flags().Lookup("merge").NoOptDefVal = domain.DefaultMergeFileName
 	rootCmd.Flags().String("separator", domain.DefaultSeparator, "Separator between chapter and page")
 	rootCmd.Flags().String("page-count-prefix", domain.DefaultPageCountPrefix, "Prefix for total page count")
+	rootCmd.Flags().String("page-count-scope", domain.DefaultPageCountScope, "Total page count in footer: handout, chapter or none")
+	rootCmd.Flags().String("footer-position", domain.DefaultFooterPosition, "Footer position: bottom-outer, bottom-inner, bottom-center, top-outer, top-inner or top-center")
+	rootCmd.Flags().Int("footer-offset-x", domain.DefaultFooterOffsetX, "Distance of the footer from the left or right edge in points")
+	rootCmd.Flags().Int("footer-offset-y", domain.DefaultFooterOffsetY, "Distance of the footer from the bottom or top edge in points")
+	rootCmd.Flags().String("footer-font", domain.DefaultFooterFont, "Footer font, a PDF core font like Helvetica, Times-Roman or Courier")
+	rootCmd.Flags().Int("footer-font-size", domain.DefaultFooterFontSize, "Footer font size in points")
+	rootCmd.Flags().String("footer-color", domain.DefaultFooterColor, "Footer color, \"r g b\" with values from 0.0 to 1.0, \"#RRGGBB\" or a name like gray")
+	rootCmd.Flags().Float64("footer-opacity", domain.DefaultFooterOpacity, "Footer opacity, from 0.0 (invisible) to 1.0 (opaque)")
+	rootCmd.Flags().String("font-file", "", "TrueType font (.ttf) for footer, running head, blank page text and table of contents, replaces --footer-font")
 	rootCmd.Flags().BoolP("toc", "o", false, "Generate table of contents")
+	rootCmd.Flags().String("toc-title", domain.DefaultTOCTitle, "Title of the table of contents")
+	rootCmd.Flags().String("report", "", "Write a run report (report.json or report.csv) to the target directory")
 
 	// Bind all flags to viper
 	if err := viper.BindPFlags(rootCmd.PersistentFlags()); err != nil {
@@ -175,15 +227,27 @@ func CreditsCmd() *cobra.Command {
 func runPDFProcessing(cmd *cobra.Command, args []string) error {
 	log.Info().Msg("Starting PDF processing")
 
-	fmt.Printf("Processing PDFs in %q\n", ActiveMinionConfig.SourceDir)
+	// flags have been parsed successfully, so errors from here on are not caused by wrong usage
+	cmd.SilenceUsage = true
 
 	// Validate configuration
 	if err := domain.ValidateConfig(&ActiveMinionConfig); err != nil {
-		return fmt.Errorf("invalid configuration: %w", err)
+		return err
 	}
 
+	if ActiveMinionConfig.DryRun {
+		fmt.Printf("Planning PDFs in %q\n", ActiveMinionConfig.SourceDir)
+		if err := pdf.PlanPDFs(cmd.Context(), &ActiveMinionConfig); err != nil {
+			return fmt.Errorf("error planning PDFs: %w", err)
+		}
+		return nil
+	}
+
+	fmt.Printf("Processing PDFs in %q\n", ActiveMinionConfig.SourceDir)
+
 	// Process PDFs
-	if err := pdf.ProcessPDFs(&ActiveMinionConfig); err != nil {
+	// the context is cancelled on interrupt, see Execute
+	if err := pdf.ProcessPDFs(cmd.Context(), &ActiveMinionConfig); err != nil {
 		return fmt.Errorf("error processing PDFs: %w", err)
 	}
 	return nil
@@ -12,11 +12,15 @@ func TestSetupFlags(t *testing.T) {
 	// Initialize the root command and setup flags
 	rootCmd := config.SetupApplication("test-version")
 
+	// processing is executed, therefore source and target need to exist
+	sourceDir := "../../sample-files-for-testing/OnePDF"
+	targetDir := t.TempDir()
+
 	// Set flag values
 	args := []string{
 		"--language", "EN",
-		"--source", "testSourceDir",
-		"--target", "testTargetDir",
+		"--source", sourceDir,
+		"--target", targetDir,
 		"--force",
 		"--evenify=false",
 	}
@@ -28,18 +32,18 @@ func TestSetupFlags(t *testing.T) {
 
 	// Verify the configuration
 	assert.Equal(t, "EN", viper.GetString("language"))
-	assert.Equal(t, "testSourceDir", viper.GetString("source"))
-	assert.Equal(t, "testTargetDir", viper.GetString("target"))
+	assert.Equal(t, sourceDir, viper.GetString("source"))
+	assert.Equal(t, targetDir, viper.GetString("target"))
 	assert.True(t, viper.GetBool("force"))
 	assert.False(t, viper.GetBool("evenify"))
 
 	// Verify the MinionConfig
-	minionConfig, err := config.ConfigureApplication(false)
+	minionConfig, err := config.ConfigureApplication(false, config.NewCobraFlagChecker(rootCmd))
 	assert.NoError(t, err, "Failed to configure application")
 
 	assert.Equal(t, domain.ParseLanguageCode("EN"), minionConfig.Language)
-	assert.Equal(t, "testSourceDir", minionConfig.SourceDir)
-	assert.Equal(t, "testTargetDir", minionConfig.TargetDir)
+	assert.Equal(t, sourceDir, minionConfig.SourceDir)
+	assert.Equal(t, targetDir, minionConfig.TargetDir)
 	assert.True(t, minionConfig.Force)
 	assert.False(t, minionConfig.Evenify)
 }
@@ -31,6 +31,18 @@ func PrintFinalConfiguration(myConfig *MinionConfig) {
 			}
 		case bool:
 			fmt.Printf("%s: %t\n", name, v)
+		case []string:
+			if len(v) > 0 {
+				fmt.Printf("%s: %s\n", name, strings.Join(v, ", "))
+			} else {
+				fmt.Printf("%s: <not set>\n", name)
+			}
+		case int:
+			fmt.Printf("%s: %d\n", name, v)
+		case int64:
+			fmt.Printf("%s: %d\n", name, v)
+		case float64:
+			fmt.Printf("%s: %g\n", name, v)
 		case language.Tag:
 			if v.String() != "" {
 				// Print language tag in a format the test expects
@@ -45,24 +57,83 @@ func PrintFinalConfiguration(myConfig *MinionConfig) {
 
 	// Print all fields using the helper function
 	printField("Source directory", myConfig.SourceDir)
+	printField("Recursive", myConfig.Recursive)
+	printField("Include", myConfig.Include)
+	printField("Exclude", myConfig.Exclude)
+	printField("Front matter", myConfig.FrontMatter)
+	printField("Back matter", myConfig.BackMatter)
+	printField("Matter numbering", myConfig.MatterNumbering)
+	printField("Order", myConfig.Order)
+	if myConfig.Order == OrderManifest {
+		printField("Manifest", myConfig.ManifestPath())
+	}
 	printField("Target directory", myConfig.TargetDir)
 	printField("Force", myConfig.Force)
+	printField("Strict", myConfig.Strict)
 	fmt.Println(strings.Repeat("=", 20))
 	printField("Verbose", myConfig.Verbose)
+	printField("Jobs", myConfig.Jobs)
 	printField("Evenify", myConfig.Evenify)
+	if myConfig.Evenify {
+		printField("Evenify policy", myConfig.EvenifyPolicy)
+		printField("Evenify scope", myConfig.EvenifyScope)
+	}
 	printField("Language", myConfig.Language)
 	printField("Personal-touch", myConfig.PersonalTouch)
+	if myConfig.PersonalTouch {
+		printField("Personal-touch image", myConfig.PersonalTouchImage)
+		printField("Personal-touch density", myConfig.PersonalTouchDensity)
+		printField("Personal-touch seed", myConfig.PersonalTouchSeed)
+	}
 	printField("Table of Contents", myConfig.TOC)
+	printField("Table of Contents title", myConfig.TOCTitle)
 	fmt.Println(strings.Repeat("=", 20))
 	printField("Running header", myConfig.RunningHeader)
+	if myConfig.RunningHeaderEven != "" || myConfig.RunningHeaderOdd != "" {
+		printField("Running header even pages", myConfig.RunningHeaderEven)
+		printField("Running header odd pages", myConfig.RunningHeaderOdd)
+	}
 	printField("Chapter prefix", myConfig.ChapterPrefix)
 	printField("Separator", myConfig.Separator)
 	printField("Page prefix", myConfig.PageNrPrefix)
 	printField("Total page count prefix", myConfig.PageCountPrefix)
+	printField("Total page count scope", myConfig.PageCountScope)
 	printField("Blank page text", myConfig.BlankPageText)
+	if myConfig.FooterTemplate != "" {
+		printField("Footer template", myConfig.FooterTemplate)
+	} else {
+		printField("Footer template", myConfig.DefaultFooterTemplate()+" (default)")
+	}
+	if myConfig.FooterTemplateEven != "" || myConfig.FooterTemplateOdd != "" {
+		printField("Footer template even pages", myConfig.FooterTemplateEven)
+		printField("Footer template odd pages", myConfig.FooterTemplateOdd)
+	}
+	printField("Course", myConfig.Course)
+	printField("Chapter number style", myConfig.ChapterNumberStyle)
+	printField("Page number style", myConfig.PageNumberStyle)
+	printField("Page numbering", myConfig.PageNumbering)
+	if myConfig.PageNumbering == PageNumberingChapter {
+		printField("Chapter page separator", myConfig.ChapterPageSeparator)
+	}
+	printField("First chapter", myConfig.FirstChapter)
+	printField("First page", myConfig.FirstPage)
+	printField("Continue from", myConfig.ContinueFrom)
+	printField("Footer position", myConfig.FooterPosition)
+	printField("Footer offset x", myConfig.FooterOffsetX)
+	printField("Footer offset y", myConfig.FooterOffsetY)
+	printField("Footer font", myConfig.FooterFont)
+	printField("Footer font size", myConfig.FooterFontSize)
+	printField("Footer color", myConfig.FooterColor)
+	printField("Footer opacity", myConfig.FooterOpacity)
+	printField("Font file", myConfig.FontFile)
 	fmt.Println(strings.Repeat("=", 20))
 	printField("Merge", myConfig.Merge)
 	printField("Merge file name", myConfig.MergeFileName)
+	printField("Report", myConfig.Report)
+	if handout := myConfig.HandoutPath(); handout != "" {
+		fmt.Println(strings.Repeat("=", 20))
+		printHandout(handout)
+	}
 }
 
 //
@@ -138,6 +209,11 @@ func SetAppVersion(v string) {
 	appVersion = v
 }
 
+// AppVersion returns the version set by SetAppVersion
+func AppVersion() string {
+	return appVersion
+}
+
 func PrintVersion() {
 	fmt.Printf("PDFminion version %s\n", appVersion)
 	fmt.Printf("Built on: %s\n", buildPlatform)
@@ -80,5 +80,5 @@ func TestPrintFinalConfigurationWithOtherLanguage(t *testing.T) {
 	// Test the output for standard source directory
 
 	// Test the output for standard source directory
-	assert.Contains(t, output, otherLanguage, "expected output to contain '%s'", otherLanguage)
+	assert.Contains(t, output, otherLanguage.String(), "expected output to contain '%s'", otherLanguage)
 }
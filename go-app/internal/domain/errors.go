@@ -0,0 +1,14 @@
+package domain
+
+import "errors"
+
+// Errors returned by PDFminion. They are usually wrapped with details,
+// therefore use errors.Is to check for them.
+// The root command maps every error to a distinct process exit code.
+var (
+	ErrInvalidConfig   = errors.New("invalid configuration")
+	ErrNoPDFsFound     = errors.New("no PDF files found")
+	ErrTargetNotEmpty  = errors.New("target directory is not empty")
+	ErrInvalidPDF      = errors.New("invalid PDF")
+	ErrWatermarkFailed = errors.New("stamping failed")
+)
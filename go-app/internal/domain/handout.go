@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+)
+
+// DefaultHandoutFile is used if it exists in the source directory and no other handout manifest is given
+const DefaultHandoutFile = "pdfminion.handout.yaml"
+
+// Handout describes the chapters of a handout in order, see DefaultHandoutFile.
+// It replaces collecting and ordering all PDF files of the source directory.
+//
+//	chapters:
+//	  - file: cover.pdf
+//	    matter: front
+//	  - file: intro.pdf
+//	    title: Introduction
+//	  - file: basics/basics.pdf
+//	    number: 3
+//	    running-header: Basics
+//	  - file: cheatsheet.pdf
+//	    numbering: false
+//	    evenify: false
+type Handout struct {
+	File     string // path of the manifest
+	Chapters []HandoutChapter
+}
+
+// HandoutChapter is a single chapter of a Handout, empty fields fall back to the configuration
+type HandoutChapter struct {
+	File          string  `mapstructure:"file"`           // relative to the source directory
+	Title         string  `mapstructure:"title"`          // empty: derived from the file name
+	Number        int     `mapstructure:"number"`         // 0: number of the previous chapter + 1
+	Numbering     *bool   `mapstructure:"numbering"`      // false: no footer with chapter and page number
+	Evenify       *bool   `mapstructure:"evenify"`        // nil: as configured
+	RunningHeader *string `mapstructure:"running-header"` // nil: as configured, empty: no running header
+	Matter        string  `mapstructure:"matter"`         // MatterFront or MatterBack, empty: numbered chapter
+}
+
+// ReadHandout reads and checks a handout manifest
+func ReadHandout(fileName string) (*Handout, error) {
+	v := viper.New()
+	v.SetConfigFile(fileName)
+	// the manifest format does not depend on the file extension
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("%w: error reading handout %s: %v", ErrInvalidConfig, fileName, err)
+	}
+
+	handout := &Handout{File: fileName}
+	if err := v.UnmarshalKey("chapters", &handout.Chapters); err != nil {
+		return nil, fmt.Errorf("%w: error reading chapters of handout %s: %v", ErrInvalidConfig, fileName, err)
+	}
+	if err := handout.check(); err != nil {
+		return nil, err
+	}
+	return handout, nil
+}
+
+// check ensures every chapter has a file, running headers are valid templates,
+// explicit chapter numbers are increasing and front and back matter enclose the chapters
+func (h *Handout) check() error {
+	if len(h.Chapters) == 0 {
+		return fmt.Errorf("%w: handout %s lists no chapters", ErrInvalidConfig, h.File)
+	}
+
+	previousNr := 0
+	previousMatter := MatterFront
+	files := make(map[string]bool, len(h.Chapters))
+	for i, chapter := range h.Chapters {
+		if chapter.File == "" {
+			return fmt.Errorf("%w: chapter %d of handout %s has no file", ErrInvalidConfig, i+1, h.File)
+		}
+		if files[filepath.Clean(chapter.File)] {
+			return fmt.Errorf("%w: %q is listed more than once in handout %s", ErrInvalidConfig, chapter.File, h.File)
+		}
+		files[filepath.Clean(chapter.File)] = true
+		if chapter.RunningHeader != nil {
+			if err := CheckTemplate(*chapter.RunningHeader); err != nil {
+				return fmt.Errorf("%w: invalid running header %q of chapter %q in handout %s: %v",
+					ErrInvalidConfig, *chapter.RunningHeader, chapter.File, h.File, err)
+			}
+		}
+		if err := h.checkMatter(chapter, previousMatter); err != nil {
+			return err
+		}
+		previousMatter = chapter.Matter
+		if chapter.Number < 0 || (chapter.Number > 0 && chapter.Number <= previousNr) {
+			return fmt.Errorf("%w: chapter %q of handout %s has number %d, it has to be greater than %d",
+				ErrInvalidConfig, chapter.File, h.File, chapter.Number, previousNr)
+		}
+		previousNr = NextChapterNr(previousNr, chapter)
+	}
+	return nil
+}
+
+// checkMatter ensures front matter is listed before and back matter after all chapters,
+// given the matter of the previous chapter (MatterFront for the first one)
+func (h *Handout) checkMatter(chapter HandoutChapter, previousMatter string) error {
+	switch chapter.Matter {
+	case MatterFront:
+		if previousMatter != MatterFront {
+			return fmt.Errorf("%w: front matter %q of handout %s has to be listed before all chapters", ErrInvalidConfig, chapter.File, h.File)
+		}
+	case MatterBack:
+	case "":
+		if previousMatter == MatterBack {
+			return fmt.Errorf("%w: chapter %q of handout %s is listed after back matter", ErrInvalidConfig, chapter.File, h.File)
+		}
+	default:
+		return fmt.Errorf("%w: chapter %q of handout %s has invalid matter %q (use %s or %s)",
+			ErrInvalidConfig, chapter.File, h.File, chapter.Matter, MatterFront, MatterBack)
+	}
+	if chapter.Matter != "" && chapter.Number != 0 {
+		return fmt.Errorf("%w: %s matter %q of handout %s cannot have a chapter number", ErrInvalidConfig, chapter.Matter, chapter.File, h.File)
+	}
+	return nil
+}
+
+// NextChapterNr returns the number of chapter, given the number of the previous chapter.
+// Front and back matter do not consume chapter numbers, previousNr is returned for them.
+func NextChapterNr(previousNr int, chapter HandoutChapter) int {
+	if chapter.Matter != "" {
+		return previousNr
+	}
+	if chapter.Number > 0 {
+		return chapter.Number
+	}
+	return previousNr + 1
+}
+
+// IsNumbered reports whether the footer with chapter and page number is added
+func (c HandoutChapter) IsNumbered() bool {
+	return c.Numbering == nil || *c.Numbering
+}
+
+// HandoutPath returns the handout manifest to be used, or an empty string if there is none
+func (c *MinionConfig) HandoutPath() string {
+	if c.Handout != "" {
+		return c.Handout
+	}
+	defaultHandout := filepath.Join(c.SourceDir, DefaultHandoutFile)
+	if _, err := os.Stat(defaultHandout); err == nil {
+		return defaultHandout
+	}
+	return ""
+}
+
+// printHandout prints the chapters of the handout with their resolved numbers and options
+func printHandout(handoutPath string) {
+	handout, err := ReadHandout(handoutPath)
+	if err != nil {
+		fmt.Printf("Handout: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Handout: %s\n", handout.File)
+	chapterNr := 0
+	for _, chapter := range handout.Chapters {
+		chapterNr = NextChapterNr(chapterNr, chapter)
+
+		title := "<from file name>"
+		if chapter.Title != "" {
+			title = fmt.Sprintf("%q", chapter.Title)
+		}
+		if chapter.Matter != "" {
+			fmt.Printf("  %5s  %s  %s", chapter.Matter, chapter.File, title)
+		} else {
+			fmt.Printf("  %5d  %s  %s", chapterNr, chapter.File, title)
+		}
+		if !chapter.IsNumbered() {
+			fmt.Print(", no numbering")
+		}
+		if chapter.Evenify != nil {
+			fmt.Printf(", evenify: %t", *chapter.Evenify)
+		}
+		if chapter.RunningHeader != nil {
+			fmt.Printf(", running header: %q", *chapter.RunningHeader)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,108 @@
+package domain_test
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+const handoutYAML = `chapters:
+  - file: intro.pdf
+    title: Introduction
+  - file: basics.pdf
+    number: 3
+    running-header: Basics
+  - file: cheatsheet.pdf
+    numbering: false
+    evenify: false
+    running-header: ""
+`
+
+func writeHandout(t *testing.T, content string) string {
+	t.Helper()
+	fileName := filepath.Join(t.TempDir(), domain.DefaultHandoutFile)
+	assert.NoError(t, os.WriteFile(fileName, []byte(content), 0644))
+	return fileName
+}
+
+func TestReadHandout(t *testing.T) {
+	handout, err := domain.ReadHandout(writeHandout(t, handoutYAML))
+	assert.NoError(t, err)
+	assert.Len(t, handout.Chapters, 3)
+
+	intro, basics, cheatsheet := handout.Chapters[0], handout.Chapters[1], handout.Chapters[2]
+	assert.Equal(t, "Introduction", intro.Title)
+	assert.True(t, intro.IsNumbered())
+	assert.Nil(t, intro.Evenify)
+	assert.Nil(t, intro.RunningHeader)
+
+	assert.Equal(t, 3, basics.Number)
+	assert.Equal(t, "Basics", *basics.RunningHeader)
+
+	assert.False(t, cheatsheet.IsNumbered())
+	assert.False(t, *cheatsheet.Evenify)
+	assert.Equal(t, "", *cheatsheet.RunningHeader)
+
+	chapterNrs := make([]int, 0, len(handout.Chapters))
+	chapterNr := 0
+	for _, chapter := range handout.Chapters {
+		chapterNr = domain.NextChapterNr(chapterNr, chapter)
+		chapterNrs = append(chapterNrs, chapterNr)
+	}
+	assert.Equal(t, []int{1, 3, 4}, chapterNrs)
+}
+
+func TestReadInvalidHandout(t *testing.T) {
+	tests := map[string]string{
+		"no chapters":        "title: nothing\n",
+		"missing file":       "chapters:\n  - title: Introduction\n",
+		"duplicate file":     "chapters:\n  - file: intro.pdf\n  - file: ./intro.pdf\n",
+		"decreasing numbers": "chapters:\n  - file: intro.pdf\n    number: 2\n  - file: basics.pdf\n    number: 2\n",
+		"not yaml":           "chapters: [",
+		"late front matter":  "chapters:\n  - file: intro.pdf\n  - file: cover.pdf\n    matter: front\n",
+		"early back matter":  "chapters:\n  - file: legal.pdf\n    matter: back\n  - file: intro.pdf\n",
+		"numbered matter":    "chapters:\n  - file: cover.pdf\n    matter: front\n    number: 1\n",
+		"invalid matter":     "chapters:\n  - file: cover.pdf\n    matter: middle\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := domain.ReadHandout(writeHandout(t, content))
+			assert.True(t, errors.Is(err, domain.ErrInvalidConfig), err)
+		})
+	}
+}
+
+func TestPrintFinalConfigurationShowsHandout(t *testing.T) {
+	myConfig := domain.NewDefaultEnglishConfig()
+	myConfig.Handout = writeHandout(t, handoutYAML)
+
+	output, err := captureOutputAndCallFunction(&myConfig)
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Handout: "+myConfig.Handout)
+	assert.Regexp(t, `1\s+intro.pdf\s+"Introduction"`, output)
+	assert.Regexp(t, `3\s+basics.pdf\s+<from file name>, running header: "Basics"`, output)
+	assert.Regexp(t, `4\s+cheatsheet.pdf\s+<from file name>, no numbering, evenify: false, running header: ""`, output)
+}
+
+func TestFrontAndBackMatterDoNotConsumeChapterNumbers(t *testing.T) {
+	handout, err := domain.ReadHandout(writeHandout(t, `chapters:
+  - file: cover.pdf
+    matter: front
+  - file: intro.pdf
+  - file: basics.pdf
+  - file: legal.pdf
+    matter: back
+`))
+	assert.NoError(t, err)
+
+	chapterNrs := make([]int, 0, len(handout.Chapters))
+	chapterNr := 0
+	for _, chapter := range handout.Chapters {
+		chapterNr = domain.NextChapterNr(chapterNr, chapter)
+		chapterNrs = append(chapterNrs, chapterNr)
+	}
+	assert.Equal(t, []int{0, 1, 2, 2}, chapterNrs)
+}
@@ -24,6 +24,7 @@ var (
 		PageCountPrefix string
 		PageNumber      string
 		BlankPageText   string
+		TOCTitle        string
 	}{
 		language.German: {
 			RunningHeader:   "",
@@ -31,6 +32,7 @@ var (
 			PageCountPrefix: "von",
 			PageNumber:      "Seite",
 			BlankPageText:   "Diese Seite bleibt absichtlich leer",
+			TOCTitle:        "Inhaltsverzeichnis",
 		},
 		language.English: {
 			RunningHeader:   DefaultRunningHeader,
@@ -38,6 +40,7 @@ var (
 			PageCountPrefix: "of",
 			PageNumber:      DefaultPageNrPrefix,
 			BlankPageText:   DefaultBlankPageText,
+			TOCTitle:        DefaultTOCTitle,
 		},
 		language.French: {
 			RunningHeader:   "",
@@ -45,6 +48,7 @@ var (
 			PageCountPrefix: "sur",
 			PageNumber:      "Page",
 			BlankPageText:   "Cette page est intentionnellement laissée vide",
+			TOCTitle:        "Table des matières",
 		},
 	}
 )
@@ -3,25 +3,126 @@ package domain
 import (
 	"github.com/rs/zerolog/log"
 	"golang.org/x/text/language"
+	"path/filepath"
 )
 
 const (
-	DefaultBlankPageText = "Intentionally left blank"
-	DefaultChapterPrefix = "Chapter"
+	DefaultBlankPageText        = "Intentionally left blank"
+	DefaultChapterNumberStyle   = NumberStyleArabic
+	DefaultChapterPageSeparator = "."
+	DefaultChapterPrefix        = "Chapter"
 	//	DefaultConfigFileName  = "pdfminion.yaml"
 	DefaultEvenify         = true
+	DefaultEvenifyPolicy   = EvenifyPolicyEven
+	DefaultEvenifyScope    = EvenifyScopeChapter
+	DefaultFooterColor     = "0.5 0.5 0.5"
+	DefaultFooterFont      = "Helvetica"
+	DefaultFooterFontSize  = 16
+	DefaultFooterOffsetX   = 20
+	DefaultFooterOffsetY   = 6
+	DefaultFooterOpacity   = 1.0
+	DefaultFooterPosition  = FooterPositionBottomOuter
+	DefaultFirstChapter    = 1
+	DefaultFirstPage       = 1
 	DefaultForce           = false
+	DefaultJobs            = 0              // one job per CPU
+	DefaultManifestFile    = "chapters.txt" // within the source directory
+	DefaultMatterNumbering = MatterNumberingNone
 	DefaultMerge           = false
 	DefaultMergeFileName   = "merged.pdf"
+	DefaultOrder           = OrderNatural
 	DefaultPageCountPrefix = "of"
+	DefaultPageCountScope  = PageCountScopeHandout
 	DefaultPageNrPrefix    = "Page"
+	DefaultPageNumberStyle = NumberStyleArabic
+	DefaultPageNumbering   = PageNumberingContinuous
 	DefaultPersonalTouch   = false
-	DefaultRunningHeader   = "" // empty
-	DefaultSeparator       = " - "
-	DefaultSourceDir       = "_pdfs"
-	DefaultTargetDir       = "_target"
-	DefaultTOC             = false
-	DefaultVerbose         = false
+	// DefaultPersonalTouchDensity is given in pages per hundred
+	DefaultPersonalTouchDensity = 10
+	DefaultPersonalTouchSeed    = 42
+	DefaultRecursive            = false
+	DefaultRunningHeader        = "" // empty
+	DefaultSeparator            = " - "
+	DefaultSourceDir            = "_pdfs"
+	DefaultStrict               = false
+	DefaultTargetDir            = "_target"
+	DefaultTOC                  = false
+	DefaultTOCFileName          = "toc.pdf"
+	DefaultTOCTitle             = "Table of Contents"
+	DefaultVerbose              = false
+)
+
+// Scopes of the total page count shown in the footer
+const (
+	// PageCountScopeHandout shows continuous page numbers and the total of all chapters, e.g. "Page 17 of 142"
+	PageCountScopeHandout = "handout"
+	// PageCountScopeChapter shows page numbers and the total within the chapter, e.g. "Page 2 of 9"
+	PageCountScopeChapter = "chapter"
+	// PageCountScopeNone shows continuous page numbers without total
+	PageCountScopeNone = "none"
+)
+
+// Footer positions: top or bottom edge, and outer margin (mirrored on even and odd pages),
+// inner margin (next to the binding) or centered
+const (
+	FooterPositionBottomOuter  = "bottom-outer"
+	FooterPositionBottomInner  = "bottom-inner"
+	FooterPositionBottomCenter = "bottom-center"
+	FooterPositionTopOuter     = "top-outer"
+	FooterPositionTopInner     = "top-inner"
+	FooterPositionTopCenter    = "top-center"
+)
+
+// Chapter orders, i.e. how the candidate files are sorted into chapters
+const (
+	// OrderNatural compares numbers within file names numerically, e.g. "2-basics.pdf" before "10-intro.pdf"
+	OrderNatural = "natural"
+	// OrderLexical compares file names character by character, e.g. "10-intro.pdf" before "2-basics.pdf"
+	OrderLexical = "lexical"
+	// OrderMtime sorts files by modification time, oldest first
+	OrderMtime = "mtime"
+	// OrderManifest takes the order from a manifest file listing all chapters
+	OrderManifest = "manifest"
+)
+
+// Formats of the run report, given by the extension of the report file
+const (
+	ReportFormatJSON = ".json"
+	ReportFormatCSV  = ".csv"
+)
+
+// Evenify policies, i.e. how blank pages are added for duplex printing
+const (
+	// EvenifyPolicyEven appends a blank page to odd page counts
+	EvenifyPolicyEven = "even"
+	// EvenifyPolicyMultipleOf4 appends up to three blank pages, e.g. for saddle-stitched signatures
+	EvenifyPolicyMultipleOf4 = "multiple-of-4"
+	// EvenifyPolicyStartRight inserts a blank page before a chapter which would start on a left-hand (even) page
+	EvenifyPolicyStartRight = "start-right"
+	// EvenifyPolicyWarn adds no blank pages, but warns about odd page counts
+	EvenifyPolicyWarn = "warn"
+)
+
+// Evenify scopes, i.e. where the blank pages of the EvenifyPolicy are added
+const (
+	// EvenifyScopeChapter adds blank pages to every chapter file
+	EvenifyScopeChapter = "chapter"
+	// EvenifyScopeMerged keeps the chapter files as they are and adds blank pages to the merged file only
+	EvenifyScopeMerged = "merged"
+)
+
+// Front and back matter, e.g. a course cover or a legal notice, are not numbered as chapters
+const (
+	MatterFront = "front" // placed before the first chapter
+	MatterBack  = "back"  // placed after the last chapter
+)
+
+// Page numbers of front and back matter, which are numbered separately from the chapters
+const (
+	// MatterNumberingNone adds neither footer nor running header
+	MatterNumberingNone = "none"
+	// MatterNumberingRoman adds lower-case roman page numbers, e.g. "iii"
+	MatterNumberingRoman = "roman"
 )
 
 // MinionConfig holds the configuration for the PDFMinion application
@@ -39,15 +140,31 @@ type MinionConfig struct {
 	Verbose             bool
 	SourceDir           string
 	SourceDirValid      bool
+	Recursive           bool     // collect PDFs from sub-folders, the folder structure is mirrored in the target directory
+	Include             []string // glob patterns, only matching files are processed. Empty: all PDFs
+	Exclude             []string // glob patterns, matching files and folders are ignored
+	FrontMatter         []string // glob patterns, matching files are front matter, see MatterFront
+	BackMatter          []string // glob patterns, matching files are back matter, see MatterBack
+	MatterNumbering     string   // one of the MatterNumbering constants
+	Order               string   // one of the Order constants
+	Manifest            string   // chapter list for OrderManifest, empty: DefaultManifestFile in SourceDir
+	Handout             string   // handout manifest, replaces collecting and ordering, see HandoutPath
 	TargetDir           string
 	TargetDirValid      bool
 	Force               bool
+	Strict              bool // abort if any candidate file is not a valid PDF
+	DryRun              bool // only show the plan, nothing is written
 
 	// Processing options
+	Jobs          int // number of files processed concurrently, 0: one per CPU
 	Evenify       bool
+	EvenifyPolicy string // one of the EvenifyPolicy constants, used if Evenify is set
+	EvenifyScope  string // one of the EvenifyScope constants
 	Merge         bool
 	MergeFileName string
 	TOC           bool // Table of Contents generation
+	TOCTitle      string
+	Report        string // run report within the target directory, .json or .csv, empty: no report
 
 	// Page formatting
 	RunningHeader   string
@@ -55,16 +172,49 @@ type MinionConfig struct {
 	Separator       string
 	PageNrPrefix    string
 	PageCountPrefix string
+	PageCountScope  string
 	BlankPageText   string
 
+	// Header and footer templates, see the Placeholder constants
+	RunningHeaderEven  string // replaces RunningHeader on even pages
+	RunningHeaderOdd   string // replaces RunningHeader on odd pages
+	FooterTemplate     string // empty: DefaultFooterTemplate, built from the prefixes
+	FooterTemplateEven string // replaces FooterTemplate on even pages
+	FooterTemplateOdd  string // replaces FooterTemplate on odd pages
+	Course             string // value of the {course} placeholder
+
+	// Numbering, used for footer, running header, table of contents, page labels and bookmarks
+	ChapterNumberStyle   string // one of the NumberStyle constants
+	PageNumberStyle      string // one of the NumberStyle constants
+	PageNumbering        string // one of the PageNumbering constants
+	ChapterPageSeparator string // between chapter and page number with PageNumberingChapter, e.g. "." for "3.7"
+	FirstChapter         int    // number of the first chapter, e.g. 8 for the second volume of a course
+	FirstPage            int    // number of the first page
+	ContinueFrom         string // report of a previous run (.json or .csv), numbering continues after its last chapter and page
+
+	// Footer layout
+	FooterPosition string  // one of the FooterPosition constants
+	FooterOffsetX  int     // points from the left or right edge, ignored for centered footers
+	FooterOffsetY  int     // points from the bottom or top edge
+	FooterFont     string  // name of a PDF core font, like Helvetica or Times-Roman
+	FooterFontSize int     // points
+	FooterColor    string  // "r g b" with intensities from 0.0 to 1.0, "#RRGGBB" or a name like "gray"
+	FooterOpacity  float64 // from 0.0 (invisible) to 1.0 (opaque)
+
+	// TrueType font for footer, running header, blank page text and table of contents,
+	// replaces FooterFont and the built-in fonts if set
+	FontFile string
+
 	// personal touch, adds funny logo to random pages
-	// TODO
-	PersonalTouch bool
+	PersonalTouch        bool
+	PersonalTouchImage   string // empty: use the embedded PDFminion mascot
+	PersonalTouchDensity int    // pages per hundred
+	PersonalTouchSeed    int64  // same seed, same pages
 
 	// Metadata to track which fields were explicitly set
 	// This is used to determine which fields to merge
 	// Note: keys are lowercase, should be converted with strings.ToLower()
-	SetFields map[string]bool
+	SetFields map[string]bool `json:"-"`
 }
 
 // NewDefaultEnglishConfig creates a new configuration with English texts
@@ -86,14 +236,21 @@ func NewDefaultConfig(systemLanguage language.Tag) MinionConfig {
 	}
 
 	defaultConfig := MinionConfig{
-		Verbose:       DefaultVerbose,
-		SourceDir:     DefaultSourceDir,
-		TargetDir:     DefaultTargetDir,
-		Force:         DefaultForce,
-		Evenify:       DefaultEvenify,
-		Merge:         DefaultMerge,
-		MergeFileName: DefaultMergeFileName,
-		TOC:           DefaultTOC,
+		Verbose:         DefaultVerbose,
+		SourceDir:       DefaultSourceDir,
+		Recursive:       DefaultRecursive,
+		Order:           DefaultOrder,
+		MatterNumbering: DefaultMatterNumbering,
+		TargetDir:       DefaultTargetDir,
+		Force:           DefaultForce,
+		Strict:          DefaultStrict,
+		Jobs:            DefaultJobs,
+		Evenify:         DefaultEvenify,
+		EvenifyPolicy:   DefaultEvenifyPolicy,
+		EvenifyScope:    DefaultEvenifyScope,
+		Merge:           DefaultMerge,
+		MergeFileName:   DefaultMergeFileName,
+		TOC:             DefaultTOC,
 		//		ConfigFileName: DefaultConfigFileName,
 		Language: systemLanguage,
 
@@ -102,11 +259,30 @@ func NewDefaultConfig(systemLanguage language.Tag) MinionConfig {
 		RunningHeader:   texts.RunningHeader,
 		PageNrPrefix:    texts.PageNumber,
 		PageCountPrefix: texts.PageCountPrefix,
+		PageCountScope:  DefaultPageCountScope,
 		BlankPageText:   texts.BlankPageText,
+		TOCTitle:        texts.TOCTitle,
 		Separator:       DefaultSeparator,
 
-		PersonalTouch: DefaultPersonalTouch,
-		SetFields:     make(map[string]bool),
+		ChapterNumberStyle:   DefaultChapterNumberStyle,
+		PageNumberStyle:      DefaultPageNumberStyle,
+		PageNumbering:        DefaultPageNumbering,
+		ChapterPageSeparator: DefaultChapterPageSeparator,
+		FirstChapter:         DefaultFirstChapter,
+		FirstPage:            DefaultFirstPage,
+
+		FooterPosition: DefaultFooterPosition,
+		FooterOffsetX:  DefaultFooterOffsetX,
+		FooterOffsetY:  DefaultFooterOffsetY,
+		FooterFont:     DefaultFooterFont,
+		FooterFontSize: DefaultFooterFontSize,
+		FooterColor:    DefaultFooterColor,
+		FooterOpacity:  DefaultFooterOpacity,
+
+		PersonalTouch:        DefaultPersonalTouch,
+		PersonalTouchDensity: DefaultPersonalTouchDensity,
+		PersonalTouchSeed:    DefaultPersonalTouchSeed,
+		SetFields:            make(map[string]bool),
 	}
 
 	return defaultConfig
@@ -129,7 +305,7 @@ func (c *MinionConfig) MergeWith(other MinionConfig) error {
 	// handle language separately:
 	// if other language is set to a supported language,
 	// set  all language-specific fields  to language-specific defaults.
-	if other.Language.String() != "" {
+	if other.Language != language.Und {
 		c.Language = other.Language
 		// only if the given language is supported, we set
 		// language-specific values
@@ -160,12 +336,99 @@ func (c *MinionConfig) MergeWith(other MinionConfig) error {
 	if other.PageCountPrefix != "" {
 		c.PageCountPrefix = other.PageCountPrefix
 	}
+	if other.PageCountScope != "" {
+		c.PageCountScope = other.PageCountScope
+	}
 	if other.BlankPageText != "" {
 		c.BlankPageText = other.BlankPageText
 	}
 	if other.Separator != "" {
 		c.Separator = other.Separator
 	}
+	if other.TOCTitle != "" {
+		c.TOCTitle = other.TOCTitle
+	}
+	if other.PersonalTouchImage != "" {
+		c.PersonalTouchImage = other.PersonalTouchImage
+	}
+	if other.Order != "" {
+		c.Order = other.Order
+	}
+	if other.Manifest != "" {
+		c.Manifest = other.Manifest
+	}
+	if other.FooterPosition != "" {
+		c.FooterPosition = other.FooterPosition
+	}
+	if other.FooterFont != "" {
+		c.FooterFont = other.FooterFont
+	}
+	if other.FooterColor != "" {
+		c.FooterColor = other.FooterColor
+	}
+	if other.FontFile != "" {
+		c.FontFile = other.FontFile
+	}
+	if other.RunningHeaderEven != "" {
+		c.RunningHeaderEven = other.RunningHeaderEven
+	}
+	if other.RunningHeaderOdd != "" {
+		c.RunningHeaderOdd = other.RunningHeaderOdd
+	}
+	if other.FooterTemplate != "" {
+		c.FooterTemplate = other.FooterTemplate
+	}
+	if other.FooterTemplateEven != "" {
+		c.FooterTemplateEven = other.FooterTemplateEven
+	}
+	if other.FooterTemplateOdd != "" {
+		c.FooterTemplateOdd = other.FooterTemplateOdd
+	}
+	if other.Course != "" {
+		c.Course = other.Course
+	}
+	if other.ChapterNumberStyle != "" {
+		c.ChapterNumberStyle = other.ChapterNumberStyle
+	}
+	if other.PageNumberStyle != "" {
+		c.PageNumberStyle = other.PageNumberStyle
+	}
+	if other.PageNumbering != "" {
+		c.PageNumbering = other.PageNumbering
+	}
+	if other.ChapterPageSeparator != "" {
+		c.ChapterPageSeparator = other.ChapterPageSeparator
+	}
+	if other.ContinueFrom != "" {
+		c.ContinueFrom = other.ContinueFrom
+	}
+	if other.EvenifyPolicy != "" {
+		c.EvenifyPolicy = other.EvenifyPolicy
+	}
+	if other.EvenifyScope != "" {
+		c.EvenifyScope = other.EvenifyScope
+	}
+	if other.Report != "" {
+		c.Report = other.Report
+	}
+	if other.Handout != "" {
+		c.Handout = other.Handout
+	}
+	if len(other.Include) > 0 {
+		c.Include = other.Include
+	}
+	if len(other.Exclude) > 0 {
+		c.Exclude = other.Exclude
+	}
+	if len(other.FrontMatter) > 0 {
+		c.FrontMatter = other.FrontMatter
+	}
+	if len(other.BackMatter) > 0 {
+		c.BackMatter = other.BackMatter
+	}
+	if other.MatterNumbering != "" {
+		c.MatterNumbering = other.MatterNumbering
+	}
 
 	// Boolean flags are only merged if they have been explicitly set.
 	// See ADR-0009 on metadata.
@@ -177,6 +440,15 @@ func (c *MinionConfig) MergeWith(other MinionConfig) error {
 	if other.SetFields["force"] {
 		c.Force = other.Force
 	}
+	if other.SetFields["strict"] {
+		c.Strict = other.Strict
+	}
+	if other.SetFields["dryrun"] {
+		c.DryRun = other.DryRun
+	}
+	if other.SetFields["recursive"] {
+		c.Recursive = other.Recursive
+	}
 	if other.SetFields["evenify"] {
 		c.Evenify = other.Evenify
 	}
@@ -186,13 +458,55 @@ func (c *MinionConfig) MergeWith(other MinionConfig) error {
 	if other.SetFields["personal"] {
 		c.PersonalTouch = other.PersonalTouch
 	}
+	// zero is a valid seed, therefore numbers are merged like booleans
+	if other.SetFields["personaldensity"] {
+		c.PersonalTouchDensity = other.PersonalTouchDensity
+	}
+	if other.SetFields["personalseed"] {
+		c.PersonalTouchSeed = other.PersonalTouchSeed
+	}
+	if other.SetFields["jobs"] {
+		c.Jobs = other.Jobs
+	}
+	if other.SetFields["footeroffsetx"] {
+		c.FooterOffsetX = other.FooterOffsetX
+	}
+	if other.SetFields["footeroffsety"] {
+		c.FooterOffsetY = other.FooterOffsetY
+	}
+	if other.SetFields["footerfontsize"] {
+		c.FooterFontSize = other.FooterFontSize
+	}
+	if other.SetFields["footeropacity"] {
+		c.FooterOpacity = other.FooterOpacity
+	}
+	if other.SetFields["firstchapter"] {
+		c.FirstChapter = other.FirstChapter
+	}
+	if other.SetFields["firstpage"] {
+		c.FirstPage = other.FirstPage
+	}
 	if other.SetFields["toc"] {
 		c.TOC = other.TOC
 	}
 
+	// keep track of the explicitly set fields, e.g. for validateContinueFrom
+	for field, set := range other.SetFields {
+		if set {
+			c.SetFields[field] = true
+		}
+	}
 	return nil
 }
 
+// ManifestPath returns the manifest file used for OrderManifest
+func (c *MinionConfig) ManifestPath() string {
+	if c.Manifest != "" {
+		return c.Manifest
+	}
+	return filepath.Join(c.SourceDir, DefaultManifestFile)
+}
+
 func (c *MinionConfig) setLanguageSpecificValues(supportedLang language.Tag) {
 	texts := DefaultTexts[supportedLang]
 	c.ChapterPrefix = texts.ChapterPrefix
@@ -200,4 +514,5 @@ func (c *MinionConfig) setLanguageSpecificValues(supportedLang language.Tag) {
 	c.PageNrPrefix = texts.PageNumber
 	c.PageCountPrefix = texts.PageCountPrefix
 	c.BlankPageText = texts.BlankPageText
+	c.TOCTitle = texts.TOCTitle
 }
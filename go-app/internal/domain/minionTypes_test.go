@@ -3,6 +3,8 @@ package domain
 import (
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/text/language"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -285,6 +287,136 @@ func TestMinionConfig_MergeBooleanOverwrite(t *testing.T) {
 	assert.Equal(t, true, minimalBaseConfig.Force)
 }
 
+// TestMinionConfig_MergeCollectionSettings tests that patterns are only overwritten if given in the other config.
+func TestMinionConfig_MergeCollectionSettings(t *testing.T) {
+	base := NewDefaultEnglishConfig()
+	base.Exclude = []string{"draft*"}
+
+	other := &MinionConfig{
+		Recursive: true,
+		Include:   []string{"ch*.pdf", "appendix/*"},
+		SetFields: map[string]bool{"recursive": true, "include": true},
+	}
+
+	assert.NoError(t, base.MergeWith(*other), "MergeWith should not return an error")
+	assert.True(t, base.Recursive)
+	assert.Equal(t, []string{"ch*.pdf", "appendix/*"}, base.Include)
+	assert.Equal(t, []string{"draft*"}, base.Exclude)
+}
+
+// TestMinionConfig_MergeFooterLayout tests that numeric footer settings are only overwritten if set in the other config.
+func TestMinionConfig_MergeFooterLayout(t *testing.T) {
+	base := NewDefaultEnglishConfig()
+
+	other := &MinionConfig{
+		FooterPosition: FooterPositionTopCenter,
+		FooterOpacity:  0.5,
+		FooterFontSize: 0,
+		SetFields:      map[string]bool{"footeropacity": true},
+	}
+
+	assert.NoError(t, base.MergeWith(*other), "MergeWith should not return an error")
+	assert.Equal(t, FooterPositionTopCenter, base.FooterPosition)
+	assert.Equal(t, 0.5, base.FooterOpacity)
+	assert.Equal(t, DefaultFooterFontSize, base.FooterFontSize)
+	assert.Equal(t, DefaultFooterFont, base.FooterFont)
+}
+
+// TestValidateFooterLayout tests that invalid footer settings are rejected
+func TestValidateFooterLayout(t *testing.T) {
+	defaults := NewDefaultEnglishConfig()
+	assert.NoError(t, defaults.validateFooterLayout())
+
+	for name, configure := range map[string]func(c *MinionConfig){
+		"position":  func(c *MinionConfig) { c.FooterPosition = "middle" },
+		"offset":    func(c *MinionConfig) { c.FooterOffsetY = -1 },
+		"font":      func(c *MinionConfig) { c.FooterFont = "NoSuchFont" },
+		"font size": func(c *MinionConfig) { c.FooterFontSize = 0 },
+		"color":     func(c *MinionConfig) { c.FooterColor = "0.5, 0.5, 0.5" },
+		"opacity":   func(c *MinionConfig) { c.FooterOpacity = 1.5 },
+	} {
+		c := NewDefaultEnglishConfig()
+		configure(&c)
+		assert.ErrorIs(t, c.validateFooterLayout(), ErrInvalidConfig, name)
+	}
+}
+
+// TestValidateFirstChapterAndPage tests the start of numbering and its continuation from a report
+func TestValidateFirstChapterAndPage(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "report.json")
+	assert.NoError(t, os.WriteFile(report, []byte(`{"chapters": []}`), 0644))
+
+	valid := NewDefaultEnglishConfig()
+	valid.ContinueFrom = report
+	assert.NoError(t, valid.validateNumbering())
+
+	for name, configure := range map[string]func(c *MinionConfig){
+		"first chapter":             func(c *MinionConfig) { c.FirstChapter = 0 },
+		"first page":                func(c *MinionConfig) { c.FirstPage = -1 },
+		"missing report":            func(c *MinionConfig) { c.ContinueFrom = report + ".missing.json" },
+		"no report":                 func(c *MinionConfig) { c.ContinueFrom = filepath.Join(filepath.Dir(report), "chapters.txt") },
+		"report with first page":    func(c *MinionConfig) { c.ContinueFrom, c.FirstPage = report, 7 },
+		"report with first chapter": func(c *MinionConfig) { c.ContinueFrom, c.FirstChapter = report, 3 },
+		"report with explicit first chapter 1": func(c *MinionConfig) {
+			c.ContinueFrom, c.FirstChapter, c.SetFields = report, 1, map[string]bool{"firstchapter": true}
+		},
+		"report with explicit first page 1": func(c *MinionConfig) {
+			c.ContinueFrom, c.FirstPage, c.SetFields = report, 1, map[string]bool{"firstpage": true}
+		},
+	} {
+		c := NewDefaultEnglishConfig()
+		configure(&c)
+		assert.ErrorIs(t, c.validateNumbering(), ErrInvalidConfig, name)
+	}
+}
+
+// TestValidateMatter tests that invalid front and back matter settings are rejected
+func TestValidateMatter(t *testing.T) {
+	valid := NewDefaultEnglishConfig()
+	valid.FrontMatter = []string{"cover*.pdf"}
+	valid.MatterNumbering = MatterNumberingRoman
+	assert.NoError(t, valid.validatePatterns())
+
+	for name, configure := range map[string]func(c *MinionConfig){
+		"pattern":   func(c *MinionConfig) { c.BackMatter = []string{"[legal"} },
+		"numbering": func(c *MinionConfig) { c.MatterNumbering = NumberStyleArabic },
+	} {
+		c := NewDefaultEnglishConfig()
+		configure(&c)
+		assert.ErrorIs(t, c.validatePatterns(), ErrInvalidConfig, name)
+	}
+}
+
+func TestValidateMergeFileName(t *testing.T) {
+	c := NewDefaultEnglishConfig()
+	c.Merge = true
+	c.TOC = true
+	assert.NoError(t, c.validateMergeFileName())
+
+	for _, name := range []string{"", "out/merged.pdf", "../merged.pdf", DefaultTOCFileName} {
+		c.MergeFileName = name
+		assert.ErrorIs(t, c.validateMergeFileName(), ErrInvalidConfig, name)
+	}
+}
+
+func TestValidateEvenify(t *testing.T) {
+	valid := NewDefaultEnglishConfig()
+	valid.EvenifyPolicy = EvenifyPolicyMultipleOf4
+	valid.EvenifyScope = EvenifyScopeMerged
+	valid.Merge = true
+	assert.NoError(t, valid.validateEvenify())
+
+	for name, configure := range map[string]func(c *MinionConfig){
+		"policy":               func(c *MinionConfig) { c.EvenifyPolicy = "odd" },
+		"scope":                func(c *MinionConfig) { c.EvenifyScope = "handout" },
+		"merged without merge": func(c *MinionConfig) { c.EvenifyScope = EvenifyScopeMerged },
+	} {
+		c := NewDefaultEnglishConfig()
+		configure(&c)
+		assert.ErrorIs(t, c.validateEvenify(), ErrInvalidConfig, name)
+	}
+}
+
 // TestMinionConfig_MergeWithPartialSuperset: A few fields are overwritten in the other config, one field (merge) was unset in base and is set in other.
 // One boolean field in other overwrites the value in base.
 func TestMinionConfig_MergeWithPartialSuperset(t *testing.T) {
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Number styles of chapter and page numbers, named like the list-style-type of CSS.
+// They are rendered like the numbering styles of PDF page labels, so PDF viewers show the same numbers.
+const (
+	NumberStyleArabic     = "arabic"      // 1, 2, 3
+	NumberStyleLowerRoman = "lower-roman" // i, ii, iii
+	NumberStyleUpperRoman = "upper-roman" // I, II, III
+	NumberStyleLowerAlpha = "lower-alpha" // a, b, c ... z, aa, bb
+	NumberStyleUpperAlpha = "upper-alpha" // A, B, C ... Z, AA, BB
+)
+
+// Page numbering schemes
+const (
+	// PageNumberingContinuous numbers the pages through the whole handout, e.g. "17"
+	PageNumberingContinuous = "continuous"
+	// PageNumberingChapter restarts the page numbers in every chapter, prefixed with the chapter number, e.g. "3.7"
+	PageNumberingChapter = "chapter"
+)
+
+var romanNumerals = []struct {
+	value   int
+	numeral string
+}{
+	{1000, "m"}, {900, "cm"}, {500, "d"}, {400, "cd"}, {100, "c"}, {90, "xc"},
+	{50, "l"}, {40, "xl"}, {10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"},
+}
+
+// IsNumberStyle reports whether style is one of the NumberStyle constants
+func IsNumberStyle(style string) bool {
+	switch style {
+	case NumberStyleArabic, NumberStyleLowerRoman, NumberStyleUpperRoman, NumberStyleLowerAlpha, NumberStyleUpperAlpha:
+		return true
+	}
+	return false
+}
+
+// FormatNumber renders n in the given style, following the numbering styles of PDF page labels:
+// roman numbers from 4000 on repeat the "m", letters repeat after "z" (aa to zz, aaa to zzz).
+// Zero and negative numbers, which have no page label, are rendered as arabic numbers.
+func FormatNumber(n int, style string) string {
+	switch style {
+	case NumberStyleLowerRoman, NumberStyleUpperRoman:
+		if n < 1 {
+			break
+		}
+		var b strings.Builder
+		for _, r := range romanNumerals {
+			for ; n >= r.value; n -= r.value {
+				b.WriteString(r.numeral)
+			}
+		}
+		if style == NumberStyleUpperRoman {
+			return strings.ToUpper(b.String())
+		}
+		return b.String()
+	case NumberStyleLowerAlpha, NumberStyleUpperAlpha:
+		if n < 1 {
+			break
+		}
+		// z is followed by aa, bb ... zz, then aaa
+		letters := strings.Repeat(string(rune('a'+(n-1)%26)), (n-1)/26+1)
+		if style == NumberStyleUpperAlpha {
+			return strings.ToUpper(letters)
+		}
+		return letters
+	}
+	return strconv.Itoa(n)
+}
+
+// FormatChapterNr renders a chapter number in ChapterNumberStyle, e.g. "3" or "C"
+func (c *MinionConfig) FormatChapterNr(chapterNr int) string {
+	return FormatNumber(chapterNr, c.ChapterNumberStyle)
+}
+
+// FormatPageNr renders a page number as configured by PageNumbering and PageNumberStyle,
+// e.g. "17" for continuous numbering or "3.2" for the second page of chapter 3
+func (c *MinionConfig) FormatPageNr(chapterNr, pageInChapter, pageNr int) string {
+	if c.PageNumbering == PageNumberingChapter {
+		return c.ChapterPagePrefix(chapterNr) + FormatNumber(pageInChapter, c.PageNumberStyle)
+	}
+	return FormatNumber(pageNr, c.PageNumberStyle)
+}
+
+// ChapterPagePrefix is shown before the page numbers of a chapter with PageNumberingChapter, e.g. "3."
+func (c *MinionConfig) ChapterPagePrefix(chapterNr int) string {
+	return c.FormatChapterNr(chapterNr) + c.ChapterPageSeparator
+}
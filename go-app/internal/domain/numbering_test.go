@@ -0,0 +1,47 @@
+package domain_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		n     int
+		style string
+		want  string
+	}{
+		{7, domain.NumberStyleArabic, "7"},
+		{4, domain.NumberStyleLowerRoman, "iv"},
+		{1994, domain.NumberStyleUpperRoman, "MCMXCIV"},
+		{4000, domain.NumberStyleUpperRoman, "MMMM"},
+		{0, domain.NumberStyleLowerRoman, "0"},
+		{1, domain.NumberStyleUpperAlpha, "A"},
+		{26, domain.NumberStyleLowerAlpha, "z"},
+		{28, domain.NumberStyleUpperAlpha, "BB"},
+		{53, domain.NumberStyleLowerAlpha, "aaa"},
+		{0, domain.NumberStyleUpperAlpha, "0"},
+		{3, "unknown", "3"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, domain.FormatNumber(tt.n, tt.style), "%d as %s", tt.n, tt.style)
+	}
+}
+
+func TestFormatPageNr(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	assert.Equal(t, "17", cfg.FormatPageNr(3, 7, 17))
+
+	cfg.PageNumberStyle = domain.NumberStyleLowerRoman
+	assert.Equal(t, "xvii", cfg.FormatPageNr(3, 7, 17))
+
+	cfg.PageNumbering = domain.PageNumberingChapter
+	cfg.PageNumberStyle = domain.NumberStyleArabic
+	assert.Equal(t, "3.7", cfg.FormatPageNr(3, 7, 17))
+
+	cfg.ChapterNumberStyle = domain.NumberStyleUpperAlpha
+	cfg.ChapterPageSeparator = "-"
+	assert.Equal(t, "A-3", cfg.FormatPageNr(1, 3, 17))
+	assert.Equal(t, "C", cfg.FormatChapterNr(3))
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Placeholders of header and footer templates, e.g. "{chapterTitle} - {page}/{total}".
+// Literal braces are written as "{{" and "}}".
+const (
+	PlaceholderChapter      = "chapter"      // chapter number
+	PlaceholderPage         = "page"         // page number within the handout
+	PlaceholderTotal        = "total"        // last page number of the handout, its page count if numbering starts at 1
+	PlaceholderChapterPage  = "chapterPage"  // page number within the chapter
+	PlaceholderChapterPages = "chapterPages" // page count of the chapter, including blank pages
+	PlaceholderChapterTitle = "chapterTitle"
+	PlaceholderFile         = "file"    // file name of the chapter
+	PlaceholderDate         = "date"    // date of the run, see TemplateDateFormat
+	PlaceholderCourse       = "course"  // see MinionConfig.Course
+	PlaceholderVersion      = "version" // version of PDFminion
+)
+
+// TemplateDateFormat is used for the {date} placeholder
+const TemplateDateFormat = "2006-01-02"
+
+var templatePlaceholders = []string{PlaceholderChapter, PlaceholderPage, PlaceholderTotal,
+	PlaceholderChapterPage, PlaceholderChapterPages, PlaceholderChapterTitle,
+	PlaceholderFile, PlaceholderDate, PlaceholderCourse, PlaceholderVersion}
+
+// TemplateValues are the values of all placeholders for a single page,
+// chapter and page numbers are formatted already, see FormatChapterNr and FormatPageNr
+type TemplateValues struct {
+	Chapter      string
+	Page         string
+	Total        int
+	ChapterPage  string
+	ChapterPages int
+	ChapterTitle string
+	File         string
+	Date         string
+	Course       string
+	Version      string
+}
+
+func (v TemplateValues) lookup(placeholder string) (string, bool) {
+	switch placeholder {
+	case PlaceholderChapter:
+		return v.Chapter, true
+	case PlaceholderPage:
+		return v.Page, true
+	case PlaceholderTotal:
+		return strconv.Itoa(v.Total), true
+	case PlaceholderChapterPage:
+		return v.ChapterPage, true
+	case PlaceholderChapterPages:
+		return strconv.Itoa(v.ChapterPages), true
+	case PlaceholderChapterTitle:
+		return v.ChapterTitle, true
+	case PlaceholderFile:
+		return v.File, true
+	case PlaceholderDate:
+		return v.Date, true
+	case PlaceholderCourse:
+		return v.Course, true
+	case PlaceholderVersion:
+		return v.Version, true
+	}
+	return "", false
+}
+
+// CheckTemplate reports unknown placeholders and unmatched braces
+func CheckTemplate(template string) error {
+	_, err := expandTemplate(template, TemplateValues{})
+	return err
+}
+
+// RenderTemplate replaces all placeholders of template by their values.
+// The template is expected to be checked, unknown placeholders are kept as they are.
+func RenderTemplate(template string, values TemplateValues) string {
+	rendered, _ := expandTemplate(template, values)
+	return rendered
+}
+
+func expandTemplate(template string, values TemplateValues) (string, error) {
+	var err error
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		switch {
+		case strings.HasPrefix(template[i:], "{{"), strings.HasPrefix(template[i:], "}}"):
+			b.WriteByte(template[i])
+			i++
+		case template[i] == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				b.WriteString(template[i:])
+				return b.String(), errors.New("unclosed \"{\", use \"{{\" for a literal brace")
+			}
+			placeholder := template[i+1 : i+end]
+			value, known := values.lookup(placeholder)
+			if !known {
+				value = template[i : i+end+1]
+				if err == nil {
+					err = fmt.Errorf("unknown placeholder {%s}, use one of {%s}",
+						placeholder, strings.Join(templatePlaceholders, "}, {"))
+				}
+			}
+			b.WriteString(value)
+			i += end
+		case template[i] == '}':
+			b.WriteByte(template[i])
+			if err == nil {
+				err = errors.New("unmatched \"}\", use \"}}\" for a literal brace")
+			}
+		default:
+			b.WriteByte(template[i])
+		}
+	}
+	return b.String(), err
+}
+
+// UsesPlaceholder reports whether template contains placeholder, e.g. PlaceholderPage
+func UsesPlaceholder(template, placeholder string) bool {
+	for i := 0; i < len(template); i++ {
+		switch {
+		case strings.HasPrefix(template[i:], "{{"), strings.HasPrefix(template[i:], "}}"):
+			i++
+		case template[i] == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return false
+			}
+			if template[i+1:i+end] == placeholder {
+				return true
+			}
+			i += end
+		}
+	}
+	return false
+}
+
+// escapeTemplate returns a template rendering text literally
+func escapeTemplate(text string) string {
+	return strings.NewReplacer("{", "{{", "}", "}}").Replace(text)
+}
+
+// DefaultFooterTemplate builds the footer from the prefixes, the separator and PageCountScope,
+// e.g. "Chapter {chapter} - Page {page} of {total}"
+func (c *MinionConfig) DefaultFooterTemplate() string {
+	footer := escapeTemplate(c.ChapterPrefix) + " {" + PlaceholderChapter + "}" +
+		escapeTemplate(c.Separator) + escapeTemplate(c.PageNrPrefix)
+
+	switch c.PageCountScope {
+	case PageCountScopeChapter:
+		return footer + " {" + PlaceholderChapterPage + "} " + escapeTemplate(c.PageCountPrefix) + " {" + PlaceholderChapterPages + "}"
+	case PageCountScopeNone:
+		return footer + " {" + PlaceholderPage + "}"
+	default:
+		return footer + " {" + PlaceholderPage + "} " + escapeTemplate(c.PageCountPrefix) + " {" + PlaceholderTotal + "}"
+	}
+}
+
+// FooterTemplateFor returns the footer template of page pageNr of the handout:
+// FooterTemplateEven or FooterTemplateOdd if set, FooterTemplate or DefaultFooterTemplate otherwise
+func (c *MinionConfig) FooterTemplateFor(pageNr int) string {
+	return pageTemplate(pageNr, c.FooterTemplateEven, c.FooterTemplateOdd, c.FooterTemplate, c.DefaultFooterTemplate())
+}
+
+// RunningHeaderFor returns the running header template of page pageNr of the handout:
+// RunningHeaderEven or RunningHeaderOdd if set, RunningHeader otherwise
+func (c *MinionConfig) RunningHeaderFor(pageNr int) string {
+	return pageTemplate(pageNr, c.RunningHeaderEven, c.RunningHeaderOdd, c.RunningHeader)
+}
+
+// pageTemplate returns even or odd, depending on pageNr, or the first non-empty fallback
+func pageTemplate(pageNr int, even, odd string, fallbacks ...string) string {
+	if pageNr%2 == 0 && even != "" {
+		return even
+	}
+	if pageNr%2 != 0 && odd != "" {
+		return odd
+	}
+	for _, fallback := range fallbacks {
+		if fallback != "" {
+			return fallback
+		}
+	}
+	return ""
+}
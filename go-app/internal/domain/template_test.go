@@ -0,0 +1,64 @@
+package domain_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	values := domain.TemplateValues{Chapter: "3", Page: "17", Total: 142, ChapterPage: "2", ChapterPages: 9,
+		ChapterTitle: "Error handling", File: "03-errors.pdf", Date: "2024-05-01", Course: "Go", Version: "1.0"}
+
+	assert.Equal(t, "Go 1.0, 2024-05-01: 3 Error handling (03-errors.pdf) 17/142, 2/9",
+		domain.RenderTemplate("{course} {version}, {date}: {chapter} {chapterTitle} ({file}) {page}/{total}, {chapterPage}/{chapterPages}", values))
+	assert.Equal(t, "{chapter} = 3", domain.RenderTemplate("{{chapter}} = {chapter}", values))
+	assert.Equal(t, "no placeholders", domain.RenderTemplate("no placeholders", values))
+}
+
+func TestCheckTemplate(t *testing.T) {
+	assert.NoError(t, domain.CheckTemplate(""))
+	assert.NoError(t, domain.CheckTemplate("{chapterTitle} - {{draft}}"))
+
+	assert.ErrorContains(t, domain.CheckTemplate("Page {pages}"), "unknown placeholder {pages}")
+	assert.ErrorContains(t, domain.CheckTemplate("Page {page"), "unclosed")
+	assert.ErrorContains(t, domain.CheckTemplate("Page page}"), "unmatched")
+}
+
+func TestUsesPlaceholder(t *testing.T) {
+	assert.True(t, domain.UsesPlaceholder("Page {page} of {total}", domain.PlaceholderPage))
+	assert.False(t, domain.UsesPlaceholder("Page {chapterPage}", domain.PlaceholderPage))
+	assert.False(t, domain.UsesPlaceholder("{{page}}", domain.PlaceholderPage))
+}
+
+func TestDefaultFooterTemplateUsesPrefixes(t *testing.T) {
+	cfg := domain.NewDefaultConfig(domain.ParseLanguageCode("de"))
+	assert.Equal(t, "Kapitel {chapter} - Seite {page} von {total}", cfg.DefaultFooterTemplate())
+
+	cfg.PageCountScope = domain.PageCountScopeChapter
+	cfg.Separator = " {} "
+	assert.Equal(t, "Kapitel {chapter} {{}} Seite {chapterPage} von {chapterPages}", cfg.DefaultFooterTemplate())
+	assert.NoError(t, domain.CheckTemplate(cfg.DefaultFooterTemplate()))
+}
+
+func TestFooterTemplateForEvenAndOddPages(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.FooterTemplateOdd = "{page}"
+	assert.Equal(t, cfg.DefaultFooterTemplate(), cfg.FooterTemplateFor(2))
+	assert.Equal(t, "{page}", cfg.FooterTemplateFor(3))
+
+	cfg.FooterTemplate = "{chapter}.{chapterPage}"
+	assert.Equal(t, "{chapter}.{chapterPage}", cfg.FooterTemplateFor(2))
+}
+
+func TestInvalidTemplateIsRejected(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = t.TempDir()
+	cfg.DryRun = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.RunningHeaderOdd = "{title}"
+	err := cfg.Validate()
+	assert.ErrorIs(t, err, domain.ErrInvalidConfig)
+	assert.ErrorContains(t, err, "running header for odd pages")
+}
@@ -2,9 +2,13 @@ package domain
 
 import (
 	"fmt"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/color"
 	"golang.org/x/text/language"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 // ValidateConfig checks the configuration for correctness
@@ -23,22 +27,289 @@ func (c *MinionConfig) Validate() error {
 		return err
 	}
 
-	// Validate target directory
-	if err := c.validateTargetDir(); err != nil {
-		return err
+	// Validate target directory, a dry run does not touch it
+	if !c.DryRun {
+		if err := c.validateTargetDir(); err != nil {
+			return err
+		}
 	}
 
 	// Validate language
 	if c.Language == language.Und {
-		return fmt.Errorf("invalid or undefined language")
+		return fmt.Errorf("%w: invalid or undefined language", ErrInvalidConfig)
+	}
+
+	if err := c.validatePatterns(); err != nil {
+		return err
+	}
+
+	if err := c.validateOrder(); err != nil {
+		return err
+	}
+
+	if err := c.validateHandout(); err != nil {
+		return err
+	}
+
+	if err := c.validateReport(); err != nil {
+		return err
+	}
+
+	if err := c.validateMergeFileName(); err != nil {
+		return err
+	}
+
+	if c.Jobs < 0 {
+		return fmt.Errorf("%w: invalid number of jobs %d (use 0 for one job per CPU)", ErrInvalidConfig, c.Jobs)
+	}
+
+	if err := c.validateEvenify(); err != nil {
+		return err
+	}
+
+	if err := c.validatePageCountScope(); err != nil {
+		return err
+	}
+
+	if err := c.validateFooterLayout(); err != nil {
+		return err
+	}
+
+	if err := c.validateTemplates(); err != nil {
+		return err
+	}
+
+	if err := c.validateNumbering(); err != nil {
+		return err
+	}
+
+	if err := c.validatePersonalTouch(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *MinionConfig) validatePatterns() error {
+	for _, pattern := range append(append([]string{}, c.Include...), c.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w: invalid include or exclude pattern %q: %v", ErrInvalidConfig, pattern, err)
+		}
+	}
+	for _, pattern := range append(append([]string{}, c.FrontMatter...), c.BackMatter...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w: invalid front or back matter pattern %q: %v", ErrInvalidConfig, pattern, err)
+		}
+	}
+	switch c.MatterNumbering {
+	case MatterNumberingNone, MatterNumberingRoman:
+		return nil
+	default:
+		return fmt.Errorf("%w: invalid matter numbering %q (use %s or %s)", ErrInvalidConfig, c.MatterNumbering,
+			MatterNumberingNone, MatterNumberingRoman)
+	}
+}
+
+func (c *MinionConfig) validateOrder() error {
+	switch c.Order {
+	case OrderNatural, OrderLexical, OrderMtime:
+		return nil
+	case OrderManifest:
+		if _, err := os.Stat(c.ManifestPath()); err != nil {
+			return fmt.Errorf("%w: manifest %q cannot be used: %v", ErrInvalidConfig, c.ManifestPath(), err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: invalid order %q (use %s, %s, %s or %s)", ErrInvalidConfig, c.Order,
+			OrderNatural, OrderLexical, OrderMtime, OrderManifest)
+	}
+}
+
+// validateHandout checks the handout manifest (if any) and that all of its files exist
+func (c *MinionConfig) validateHandout() error {
+	handoutPath := c.HandoutPath()
+	if handoutPath == "" {
+		return nil
+	}
+	handout, err := ReadHandout(handoutPath)
+	if err != nil {
+		return err
+	}
+	for _, chapter := range handout.Chapters {
+		if _, err := os.Stat(filepath.Join(c.SourceDir, chapter.File)); err != nil {
+			return fmt.Errorf("%w: chapter %q of handout %s cannot be used: %v", ErrInvalidConfig, chapter.File, handoutPath, err)
+		}
+	}
+	return nil
+}
+
+// validateReport ensures the report is written to the target directory in a supported format
+func (c *MinionConfig) validateReport() error {
+	if c.Report == "" {
+		return nil
 	}
+	report := filepath.Clean(c.Report)
+	if filepath.IsAbs(report) || report == ".." || strings.HasPrefix(report, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: report %q has to be a file name within the target directory", ErrInvalidConfig, c.Report)
+	}
+	switch strings.ToLower(filepath.Ext(c.Report)) {
+	case ReportFormatJSON, ReportFormatCSV:
+		return nil
+	default:
+		return fmt.Errorf("%w: report %q has to be a %s or %s file", ErrInvalidConfig, c.Report, ReportFormatJSON, ReportFormatCSV)
+	}
+}
 
+// validateMergeFileName ensures that the merged file is written into the target directory
+// and does not replace the table of contents
+func (c *MinionConfig) validateMergeFileName() error {
+	if !c.Merge {
+		return nil
+	}
+	if c.MergeFileName == "" || strings.ContainsRune(c.MergeFileName, '/') || strings.ContainsRune(c.MergeFileName, filepath.Separator) {
+		return fmt.Errorf("%w: merge file %q has to be a file name without directory", ErrInvalidConfig, c.MergeFileName)
+	}
+	if c.TOC && strings.EqualFold(c.MergeFileName, DefaultTOCFileName) {
+		return fmt.Errorf("%w: merge file %q would replace the table of contents", ErrInvalidConfig, c.MergeFileName)
+	}
+	return nil
+}
+
+func (c *MinionConfig) validatePersonalTouch() error {
+	if !c.PersonalTouch {
+		return nil
+	}
+	if c.PersonalTouchDensity < 1 || c.PersonalTouchDensity > 100 {
+		return fmt.Errorf("%w: invalid personal touch density %d (use 1 to 100 pages per hundred)", ErrInvalidConfig, c.PersonalTouchDensity)
+	}
+	if c.PersonalTouchImage != "" {
+		if _, err := os.Stat(c.PersonalTouchImage); err != nil {
+			return fmt.Errorf("%w: personal touch image %q cannot be used: %v", ErrInvalidConfig, c.PersonalTouchImage, err)
+		}
+	}
+	return nil
+}
+
+func (c *MinionConfig) validateFooterLayout() error {
+	switch c.FooterPosition {
+	case FooterPositionBottomOuter, FooterPositionBottomInner, FooterPositionBottomCenter,
+		FooterPositionTopOuter, FooterPositionTopInner, FooterPositionTopCenter:
+	default:
+		return fmt.Errorf("%w: invalid footer position %q (use bottom-outer, bottom-inner, bottom-center, top-outer, top-inner or top-center)",
+			ErrInvalidConfig, c.FooterPosition)
+	}
+	if c.FooterOffsetX < 0 || c.FooterOffsetY < 0 {
+		return fmt.Errorf("%w: invalid footer offset %d,%d (offsets are points from the edge, at least 0)",
+			ErrInvalidConfig, c.FooterOffsetX, c.FooterOffsetY)
+	}
+	if !font.SupportedFont(c.FooterFont) {
+		return fmt.Errorf("%w: unsupported footer font %q (use a PDF core font like Helvetica, Times-Roman or Courier)",
+			ErrInvalidConfig, c.FooterFont)
+	}
+	if c.FooterFontSize < 1 || c.FooterFontSize > 200 {
+		return fmt.Errorf("%w: invalid footer font size %d (use 1 to 200 points)", ErrInvalidConfig, c.FooterFontSize)
+	}
+	// color values with commas would break the description passed to pdfcpu
+	if _, err := color.ParseColor(c.FooterColor); err != nil || strings.Contains(c.FooterColor, ",") {
+		return fmt.Errorf("%w: invalid footer color %q (use \"r g b\" with values from 0.0 to 1.0, \"#RRGGBB\" or a name like gray)",
+			ErrInvalidConfig, c.FooterColor)
+	}
+	if c.FooterOpacity <= 0 || c.FooterOpacity > 1 {
+		return fmt.Errorf("%w: invalid footer opacity %g (use more than 0.0 up to 1.0)", ErrInvalidConfig, c.FooterOpacity)
+	}
+	return c.validateFontFile()
+}
+
+// validateFontFile checks the font file exists, it is installed when processing starts
+func (c *MinionConfig) validateFontFile() error {
+	if c.FontFile == "" {
+		return nil
+	}
+	if !strings.EqualFold(filepath.Ext(c.FontFile), ".ttf") {
+		return fmt.Errorf("%w: font file %q is not a TrueType font (.ttf)", ErrInvalidConfig, c.FontFile)
+	}
+	if _, err := os.Stat(c.FontFile); err != nil {
+		return fmt.Errorf("%w: font file %q cannot be used: %v", ErrInvalidConfig, c.FontFile, err)
+	}
+	return nil
+}
+
+func (c *MinionConfig) validateEvenify() error {
+	switch c.EvenifyPolicy {
+	case EvenifyPolicyEven, EvenifyPolicyMultipleOf4, EvenifyPolicyStartRight, EvenifyPolicyWarn:
+	default:
+		return fmt.Errorf("%w: invalid evenify policy %q (use %s, %s, %s or %s)", ErrInvalidConfig, c.EvenifyPolicy,
+			EvenifyPolicyEven, EvenifyPolicyMultipleOf4, EvenifyPolicyStartRight, EvenifyPolicyWarn)
+	}
+	switch c.EvenifyScope {
+	case EvenifyScopeChapter:
+		return nil
+	case EvenifyScopeMerged:
+		// without merged file, the chapters would not be evenified at all
+		if c.Evenify && !c.Merge {
+			return fmt.Errorf("%w: evenify scope %s requires a merged file (use --merge)", ErrInvalidConfig, EvenifyScopeMerged)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: invalid evenify scope %q (use %s or %s)", ErrInvalidConfig, c.EvenifyScope,
+			EvenifyScopeChapter, EvenifyScopeMerged)
+	}
+}
+
+func (c *MinionConfig) validatePageCountScope() error {
+	switch c.PageCountScope {
+	case PageCountScopeHandout, PageCountScopeChapter, PageCountScopeNone:
+		return nil
+	default:
+		return fmt.Errorf("%w: invalid page count scope %q (use %s, %s or %s)", ErrInvalidConfig, c.PageCountScope,
+			PageCountScopeHandout, PageCountScopeChapter, PageCountScopeNone)
+	}
+}
+
+func (c *MinionConfig) validateNumbering() error {
+	for _, style := range []string{c.ChapterNumberStyle, c.PageNumberStyle} {
+		if !IsNumberStyle(style) {
+			return fmt.Errorf("%w: invalid number style %q (use %s, %s, %s, %s or %s)", ErrInvalidConfig, style,
+				NumberStyleArabic, NumberStyleLowerRoman, NumberStyleUpperRoman, NumberStyleLowerAlpha, NumberStyleUpperAlpha)
+		}
+	}
+	switch c.PageNumbering {
+	case PageNumberingContinuous, PageNumberingChapter:
+	default:
+		return fmt.Errorf("%w: invalid page numbering %q (use %s or %s)", ErrInvalidConfig, c.PageNumbering,
+			PageNumberingContinuous, PageNumberingChapter)
+	}
+	if c.FirstChapter < 1 || c.FirstPage < 1 {
+		return fmt.Errorf("%w: invalid first chapter %d or first page %d (numbers start at 1)", ErrInvalidConfig, c.FirstChapter, c.FirstPage)
+	}
+	return c.validateContinueFrom()
+}
+
+// validateContinueFrom checks the report to continue from exists, it is read when planning starts.
+// The report determines first chapter and page, which therefore must not be given as well,
+// not even with their default values.
+func (c *MinionConfig) validateContinueFrom() error {
+	if c.ContinueFrom == "" {
+		return nil
+	}
+	if c.SetFields["firstchapter"] || c.SetFields["firstpage"] ||
+		c.FirstChapter != DefaultFirstChapter || c.FirstPage != DefaultFirstPage {
+		return fmt.Errorf("%w: continue from %q cannot be combined with first chapter or first page", ErrInvalidConfig, c.ContinueFrom)
+	}
+	switch strings.ToLower(filepath.Ext(c.ContinueFrom)) {
+	case ReportFormatJSON, ReportFormatCSV:
+	default:
+		return fmt.Errorf("%w: continue from %q has to be a %s or %s report", ErrInvalidConfig, c.ContinueFrom, ReportFormatJSON, ReportFormatCSV)
+	}
+	if _, err := os.Stat(c.ContinueFrom); err != nil {
+		return fmt.Errorf("%w: report %q cannot be continued: %v", ErrInvalidConfig, c.ContinueFrom, err)
+	}
 	return nil
 }
 
 func (c *MinionConfig) validateSourceDir() error {
 	if _, err := os.Stat(c.SourceDir); os.IsNotExist(err) {
-		return fmt.Errorf("source directory %q does not exist", c.SourceDir)
+		return fmt.Errorf("%w: source directory %q does not exist", ErrInvalidConfig, c.SourceDir)
 	}
 	return nil
 }
@@ -46,7 +317,7 @@ func (c *MinionConfig) validateSourceDir() error {
 func (c *MinionConfig) validateTargetDir() error {
 	if _, err := os.Stat(c.TargetDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(c.TargetDir, os.ModePerm); err != nil {
-			return fmt.Errorf("failed to create target directory %q: %w", c.TargetDir, err)
+			return fmt.Errorf("%w: failed to create target directory %q: %v", ErrInvalidConfig, c.TargetDir, err)
 		}
 		return nil
 	}
@@ -57,7 +328,7 @@ func (c *MinionConfig) validateTargetDir() error {
 			return err
 		}
 		if !empty {
-			return fmt.Errorf("target directory %q is not empty (use --force to override)", c.TargetDir)
+			return fmt.Errorf("%w: %q (use --force to override)", ErrTargetNotEmpty, c.TargetDir)
 		}
 	}
 
@@ -80,3 +351,21 @@ func isDirEmpty(dir string) (bool, error) {
 	// Directory is empty if we got EOF (no entries)
 	return len(names) == 0, nil
 }
+
+// validateTemplates checks the placeholders of all header and footer templates
+func (c *MinionConfig) validateTemplates() error {
+	templates := []struct{ name, template string }{
+		{"running header", c.RunningHeader},
+		{"running header for even pages", c.RunningHeaderEven},
+		{"running header for odd pages", c.RunningHeaderOdd},
+		{"footer template", c.FooterTemplate},
+		{"footer template for even pages", c.FooterTemplateEven},
+		{"footer template for odd pages", c.FooterTemplateOdd},
+	}
+	for _, t := range templates {
+		if err := CheckTemplate(t.template); err != nil {
+			return fmt.Errorf("%w: invalid %s %q: %v", ErrInvalidConfig, t.name, t.template, err)
+		}
+	}
+	return nil
+}
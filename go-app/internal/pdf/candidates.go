@@ -0,0 +1,81 @@
+package pdf
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// candidateFilter decides which files of the source directory are candidate PDFs.
+// Patterns are matched case-insensitively: patterns containing a slash are matched
+// against the path relative to the source directory, all others against the name only.
+type candidateFilter struct {
+	recursive bool
+	include   []string
+	exclude   []string
+	skipDir   string // not collected, e.g. the target directory within the source directory
+}
+
+// collectCandidates walks sourceDir and returns all files with a .pdf extension (in any case)
+// which pass the filter. Sub-folders are only visited if recursive is set,
+// hidden folders (like staging directories of interrupted runs) are never visited.
+func (f candidateFilter) collectCandidates(sourceDir string) ([]string, error) {
+	var files []string
+
+	skipDir, _ := filepath.Abs(f.skipDir)
+
+	err := filepath.WalkDir(sourceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if relPath == "." {
+				return nil
+			}
+			if !f.recursive || strings.HasPrefix(d.Name(), ".") || matchesAny(f.exclude, relPath) {
+				return filepath.SkipDir
+			}
+			if absPath, _ := filepath.Abs(path); f.skipDir != "" && absPath == skipDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.EqualFold(filepath.Ext(path), ".pdf") {
+			return nil
+		}
+		if len(f.include) > 0 && !matchesAny(f.include, relPath) {
+			return nil
+		}
+		if matchesAny(f.exclude, relPath) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files, err
+}
+
+// matchesAny reports whether relPath matches one of the glob patterns
+func matchesAny(patterns []string, relPath string) bool {
+	relPath = strings.ToLower(filepath.ToSlash(relPath))
+	name := relPath[strings.LastIndex(relPath, "/")+1:]
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(filepath.ToSlash(pattern))
+		subject := name
+		if strings.Contains(pattern, "/") {
+			subject = relPath
+		}
+		// invalid patterns have been rejected by the configuration validation
+		if matched, _ := filepath.Match(pattern, subject); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package pdf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+// createFiles creates empty files (and their folders) below dir
+func createFiles(t *testing.T, dir string, relPaths ...string) {
+	t.Helper()
+	for _, relPath := range relPaths {
+		fileName := filepath.Join(dir, filepath.FromSlash(relPath))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fileName), os.ModePerm))
+		writeFile(t, fileName, "")
+	}
+}
+
+// relPaths returns the given files relative to dir, with forward slashes
+func relPaths(t *testing.T, dir string, files []string) []string {
+	t.Helper()
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		relPath, err := filepath.Rel(dir, file)
+		assert.NoError(t, err)
+		paths = append(paths, filepath.ToSlash(relPath))
+	}
+	return paths
+}
+
+func TestCollectCandidates(t *testing.T) {
+	sourceDir := t.TempDir()
+	createFiles(t, sourceDir,
+		"Intro.PDF", "notes.txt", "draft-outline.pdf",
+		"part1/ch1.pdf", "part1/deep/ch2.Pdf",
+		"drafts/ch3.pdf", ".pdfminion-staging/ch4.pdf", "_target/ch1.pdf")
+
+	tests := []struct {
+		name   string
+		filter candidateFilter
+		want   []string
+	}{
+		{"non-recursive, any case",
+			candidateFilter{},
+			[]string{"Intro.PDF", "draft-outline.pdf"}},
+		{"recursive",
+			candidateFilter{recursive: true, skipDir: filepath.Join(sourceDir, "_target")},
+			[]string{"Intro.PDF", "draft-outline.pdf", "drafts/ch3.pdf", "part1/ch1.pdf", "part1/deep/ch2.Pdf"}},
+		{"exclude names and folders",
+			candidateFilter{recursive: true, exclude: []string{"draft*", "_target"}},
+			[]string{"Intro.PDF", "part1/ch1.pdf", "part1/deep/ch2.Pdf"}},
+		{"include names case-insensitively",
+			candidateFilter{recursive: true, include: []string{"CH*"}, exclude: []string{"_target"}},
+			[]string{"drafts/ch3.pdf", "part1/ch1.pdf", "part1/deep/ch2.Pdf"}},
+		{"include relative paths",
+			candidateFilter{recursive: true, include: []string{"part1/*"}},
+			[]string{"part1/ch1.pdf"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := tt.filter.collectCandidates(sourceDir)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, relPaths(t, sourceDir, files))
+		})
+	}
+}
+
+func TestRecursiveRunMirrorsSubFolders(t *testing.T) {
+	sourceDir := t.TempDir()
+	for relPath, sample := range map[string]string{
+		"Intro.PDF":             "sample-A4-portrait-1pg.pdf",
+		"part1/ch1.pdf":         "sample-A4-portrait-3pgs.pdf",
+		"part1/exercises/a.pdf": "sample-A4-portrait-1pg.pdf",
+	} {
+		content, err := os.ReadFile(filepath.Join(sampleDir, sample))
+		assert.NoError(t, err)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(sourceDir, relPath)), os.ModePerm))
+		assert.NoError(t, os.WriteFile(filepath.Join(sourceDir, relPath), content, 0644))
+	}
+
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sourceDir
+	cfg.TargetDir = t.TempDir()
+	cfg.Recursive = true
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, result.Files, 3)
+
+	outputPaths := make([]string, 0, len(result.Files))
+	for _, file := range result.Files {
+		assert.FileExists(t, file.OutputPath)
+		outputPaths = append(outputPaths, file.OutputPath)
+	}
+	assert.Equal(t, []string{"Intro.PDF", "part1/ch1.pdf", "part1/exercises/a.pdf"}, relPaths(t, cfg.TargetDir, outputPaths))
+}
@@ -0,0 +1,128 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"io"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"pdfminion/internal/util"
+)
+
+// blankPagesInFiles reports whether blank pages are added to the chapter files,
+// otherwise they are added to the merged file only, see EvenifyScope
+func (p *Processor) blankPagesInFiles() bool {
+	return p.config.EvenifyScope != domain.EvenifyScopeMerged
+}
+
+// blankPagesToAppend returns the number of blank pages appended to pageCount pages by the EvenifyPolicy
+func (p *Processor) blankPagesToAppend(pageCount int) int {
+	switch p.config.EvenifyPolicy {
+	case domain.EvenifyPolicyEven:
+		return pageCount % 2
+	case domain.EvenifyPolicyMultipleOf4:
+		return (4 - pageCount%4) % 4
+	}
+	// start-right inserts blank pages before chapters instead, warn adds none
+	return 0
+}
+
+// planBlankPages determines the blank pages of file, given the number of pages before it in the merged output
+// (front matter, table of contents, chapters and blank pages). The page numbers are not used, as front matter
+// is numbered separately and FirstPage shifts the numbers.
+// With EvenifyScopeMerged, the chapter files are kept as they are: only blank pages inserted before a chapter
+// are planned (as they shift the page numbers), they are added when merging.
+func (p *Processor) planBlankPages(file *SingleFileToProcess, pagesBefore int) {
+	if !p.evenifies(*file) {
+		return
+	}
+
+	if p.config.EvenifyPolicy == domain.EvenifyPolicyStartRight {
+		// the first page of the merged output is a right-hand page, and so is every other page
+		if !util.IsEven(pagesBefore) {
+			if p.blankPagesInFiles() {
+				file.BlankPagesBefore = 1
+				file.PageCount++
+			} else {
+				file.mergedBlankPagesBefore = 1
+			}
+		}
+		return
+	}
+
+	if p.blankPagesInFiles() {
+		file.BlankPagesAdded = p.blankPagesToAppend(file.PageCount)
+		file.PageCount += file.BlankPagesAdded
+	}
+}
+
+// evenifyWarnings reports files with an odd page count, which are followed by a chapter
+// starting on a left-hand page. Like planBlankPages, it counts the pages before every file in the merged output,
+// so an odd page count following another one is not reported. Warnings are given for EvenifyPolicyWarn only.
+func (p *Processor) evenifyWarnings(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) []string {
+	if !p.config.Evenify || p.config.EvenifyPolicy != domain.EvenifyPolicyWarn {
+		return nil
+	}
+
+	var warnings []string
+	// pagesBefore counts the pages preceding a file in the merged output
+	pagesBefore := 0
+	frontMatter := frontMatterCount(nrOfValidPDFs, pdfFiles)
+	for i := 0; i < nrOfValidPDFs; i++ {
+		if i == frontMatter && p.config.TOC {
+			pagesBefore += p.tocPageCount(nrOfValidPDFs - frontMatter - backMatterCount(nrOfValidPDFs, pdfFiles))
+		}
+		// a file starts on a left-hand page, if an odd number of pages precedes it
+		if i > 0 && p.evenifies(pdfFiles[i-1]) && !util.IsEven(pdfFiles[i-1].PageCount) && !util.IsEven(pagesBefore) {
+			warnings = append(warnings, fmt.Sprintf("%s has an odd page count (%d), %s starts on a left-hand page",
+				p.relativeToSource(pdfFiles[i-1].SourcePath), pdfFiles[i-1].PageCount, p.relativeToSource(pdfFiles[i].SourcePath)))
+		}
+		pagesBefore += pdfFiles[i].PageCount
+	}
+	return warnings
+}
+
+// printWarnings lists all warnings, e.g. about odd page counts
+func printWarnings(w io.Writer, warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
+}
+
+// blankPagesFile returns a file within the output directory with pageCount blank pages,
+// stamped with the blank page text. It is used to add blank pages to the merged file.
+func (p *Processor) blankPagesFile(pageCount int) (string, error) {
+	blankFile := filepath.Join(p.outputDir, fmt.Sprintf(".blank-%d.pdf", pageCount))
+	if _, err := os.Stat(blankFile); err == nil {
+		return blankFile, nil
+	}
+
+	blankPDF, err := createBlankPDF(pageCount)
+	if err != nil {
+		return "", fmt.Errorf("error creating blank pages: %w", err)
+	}
+
+	wmcs := make(map[int][]*model.Watermark, pageCount)
+	for page := 1; page <= pageCount; page++ {
+		wm, err := api.TextWatermark(p.config.BlankPageText, "font:"+p.stampFont(blankPageFont)+", "+blankPageDescription, true, false, types.POINTS)
+		if err != nil {
+			return "", fmt.Errorf("blank page text: %w", err)
+		}
+		wmcs[page] = []*model.Watermark{wm}
+	}
+
+	out, err := os.Create(blankFile)
+	if err != nil {
+		return "", fmt.Errorf("error creating file %s: %w", blankFile, err)
+	}
+	defer out.Close()
+
+	if err := api.AddWatermarksSliceMap(bytes.NewReader(blankPDF), out, wmcs, p.relaxedConf); err != nil {
+		return "", fmt.Errorf("error writing blank pages %s: %w", blankFile, err)
+	}
+	return blankFile, out.Close()
+}
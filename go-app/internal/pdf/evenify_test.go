@@ -0,0 +1,174 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func TestPlanChaptersWithMultipleOf4(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.EvenifyPolicy = domain.EvenifyPolicyMultipleOf4
+	files := []SingleFileToProcess{{PageCount: 1}, {PageCount: 3}, {PageCount: 8}}
+
+	NewProcessor(cfg).PlanChapters(len(files), files)
+
+	assert.Equal(t, []int{3, 1, 0}, []int{files[0].BlankPagesAdded, files[1].BlankPagesAdded, files[2].BlankPagesAdded})
+	assert.Equal(t, 5, files[1].FirstPageNr)
+	assert.Equal(t, 16, files[2].LastPageNr)
+}
+
+func TestPlanChaptersStartingRight(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.EvenifyPolicy = domain.EvenifyPolicyStartRight
+	files := []SingleFileToProcess{{PageCount: 3}, {PageCount: 2}, {PageCount: 1}}
+
+	NewProcessor(cfg).PlanChapters(len(files), files)
+
+	// chapter 2 would start on page 4, chapter 3 starts on page 7 anyway
+	assert.Equal(t, []int{0, 1, 0}, []int{files[0].BlankPagesBefore, files[1].BlankPagesBefore, files[2].BlankPagesBefore})
+	assert.Zero(t, files[1].BlankPagesAdded)
+	assert.Equal(t, 4, files[1].FirstPageNr)
+	assert.Equal(t, 3, files[1].PageCount)
+	assert.Equal(t, 7, files[2].FirstPageNr)
+}
+
+func TestPlanChaptersStartingRightAfterFrontMatter(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.EvenifyPolicy = domain.EvenifyPolicyStartRight
+	files := []SingleFileToProcess{{PageCount: 1, Matter: domain.MatterFront}, {PageCount: 3}, {PageCount: 2}}
+
+	NewProcessor(cfg).PlanChapters(len(files), files)
+
+	// the front matter is page 1 of the merged output, so both chapters need a blank page before them,
+	// although their page numbers start at 1 and 5
+	assert.Equal(t, []int{0, 1, 1}, []int{files[0].BlankPagesBefore, files[1].BlankPagesBefore, files[2].BlankPagesBefore})
+	assert.Equal(t, 1, files[1].FirstPageNr)
+	assert.Equal(t, 5, files[2].FirstPageNr)
+}
+
+func TestPlanChaptersStartingRightWithFirstPage(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.EvenifyPolicy = domain.EvenifyPolicyStartRight
+	cfg.FirstPage = 42
+	files := []SingleFileToProcess{{PageCount: 3}, {PageCount: 2}}
+
+	NewProcessor(cfg).PlanChapters(len(files), files)
+
+	// the first chapter starts the merged output, the second one would start on its fourth page
+	assert.Equal(t, []int{0, 1}, []int{files[0].BlankPagesBefore, files[1].BlankPagesBefore})
+	assert.Equal(t, 42, files[0].FirstPageNr)
+	assert.Equal(t, 45, files[1].FirstPageNr)
+}
+
+func TestPlanWarnsAboutOddPageCounts(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.EvenifyPolicy = domain.EvenifyPolicyWarn
+
+	plan, err := NewProcessor(cfg).Plan(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, plan.TotalPageCount)
+	assert.Zero(t, plan.Chapters[0].BlankPagesAdded)
+	// the last chapter is not followed by another one
+	assert.Equal(t, []string{"sample-A4-portrait-1pg.pdf has an odd page count (1), sample-A4-portrait-3pgs.pdf starts on a left-hand page"},
+		plan.Warnings)
+
+	cfg.SourceDir = sampleDir
+	cfg.Handout = filepath.Join(t.TempDir(), domain.DefaultHandoutFile)
+	writeFile(t, cfg.Handout, `chapters:
+  - file: sample-A4-portrait-1pg.pdf
+  - file: sample-A4-portrait-3pgs.pdf
+  - file: OnePDF/sample-A4-portrait-1pg.pdf
+`)
+
+	plan, err = NewProcessor(cfg).Plan(context.Background())
+	assert.NoError(t, err)
+
+	// the third chapter follows an odd page count, too, but starts on page 5, a right-hand page
+	assert.Equal(t, 5, plan.Chapters[2].FirstPageNr)
+	assert.Equal(t, []string{"sample-A4-portrait-1pg.pdf has an odd page count (1), sample-A4-portrait-3pgs.pdf starts on a left-hand page"},
+		plan.Warnings)
+}
+
+func TestRunWithStartRightInChapterFiles(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.EvenifyPolicy = domain.EvenifyPolicyStartRight
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+
+	// the blank page is inserted before the second chapter, which starts on page 3
+	pageCount, err := api.PageCountFile(result.Files[1].OutputPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, pageCount)
+	assert.Equal(t, 1, result.Files[1].BlankPagesBefore)
+	assert.Equal(t, []string{"0 D 2"}, pageLabels(t, result.Files[1].OutputPath))
+}
+
+func TestRunWithMergedScope(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "OnePDF"
+	cfg.TargetDir = t.TempDir()
+	cfg.EvenifyPolicy = domain.EvenifyPolicyMultipleOf4
+	cfg.EvenifyScope = domain.EvenifyScopeMerged
+	cfg.Merge = true
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+
+	// the chapter file is kept as it is, only the merged file is padded
+	pageCount, err := api.PageCountFile(result.Files[0].OutputPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pageCount)
+	assert.Zero(t, result.Files[0].BlankPagesAdded)
+
+	pageCount, err = api.PageCountFile(filepath.Join(cfg.TargetDir, cfg.MergeFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, pageCount)
+	assert.Equal(t, 3, result.MergedBlankPagesAdded)
+}
+
+func TestRunWithStartRightInMergedFile(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.EvenifyPolicy = domain.EvenifyPolicyStartRight
+	cfg.EvenifyScope = domain.EvenifyScopeMerged
+	cfg.Merge = true
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, result.Files[1].FirstPageNr)
+	mergedFile := filepath.Join(cfg.TargetDir, cfg.MergeFileName)
+	pageCount, err := api.PageCountFile(mergedFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, pageCount)
+	// the inserted blank page continues the numbering of the first chapter
+	assert.Equal(t, []string{"0 D 1", "1 D 2"}, pageLabels(t, mergedFile))
+}
+
+func TestPlanWithStartRightInMergedFile(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.EvenifyPolicy = domain.EvenifyPolicyStartRight
+	cfg.EvenifyScope = domain.EvenifyScopeMerged
+	cfg.Merge = true
+
+	plan, err := NewProcessor(cfg).Plan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, plan.Chapters[1].MergedBlankPagesBefore)
+	assert.Equal(t, 3, plan.Chapters[1].FirstPageNr)
+
+	var out bytes.Buffer
+	PrintPlan(&out, plan)
+	assert.Regexp(t, `sample-A4-portrait-3pgs\.pdf\s+3\s+\+1 before \(merged\)\s+3\s+5`, out.String())
+}
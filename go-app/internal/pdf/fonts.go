@@ -0,0 +1,215 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/rs/zerolog"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"strings"
+	"sync"
+)
+
+// installedFonts maps font files to the names they are installed with.
+// pdfcpu keeps a single font registry, so fonts are installed once and shared by all running processors.
+// They are installed into dir, a temporary directory of this process, the user's pdfcpu font
+// directory is left unchanged. Once the last run using them has finished, dir is removed,
+// the fonts are unregistered and pdfcpu's font directory is restored to previousDir.
+var installedFonts = struct {
+	sync.Mutex
+	dir         string
+	previousDir string
+	users       int
+	names       map[string]string
+	registered  []string // names added to pdfcpu's font registry
+}{names: make(map[string]string)}
+
+// fontRegistry guards pdfcpu's font registry, which is read by running processors without locking:
+// Run and Plan hold a read lock, installing and removing fonts takes the write lock
+var fontRegistry sync.RWMutex
+
+// useFontFile installs the configured FontFile for a single run and read-locks pdfcpu's font registry.
+// The returned function has to be called when the run has finished.
+func (p *Processor) useFontFile() (func(), error) {
+	if p.config.FontFile == "" {
+		fontRegistry.RLock()
+		return fontRegistry.RUnlock, nil
+	}
+
+	name, err := installFont(p.config.FontFile, p.logger)
+	if err != nil {
+		return nil, err
+	}
+	p.fontName = name
+	fontRegistry.RLock()
+	return func() {
+		fontRegistry.RUnlock()
+		releaseFonts(p.logger)
+	}, nil
+}
+
+// installFont installs the TrueType font fontFile into pdfcpu's font registry and returns its name.
+// pdfcpu embeds the glyphs used by stamped text as a font subset.
+// A font that is registered already, e.g. installed with pdfcpu by the user, is used as it is.
+// Every successful call has to be followed by releaseFonts.
+func installFont(fontFile string, logger zerolog.Logger) (string, error) {
+	installedFonts.Lock()
+	defer installedFonts.Unlock()
+
+	if name, ok := installedFonts.names[fontFile]; ok {
+		installedFonts.users++
+		return name, nil
+	}
+
+	// pdfcpu names installed fonts by their PostScript name, which is known only after installing,
+	// so the font is installed into an empty directory first
+	tmpDir, err := os.MkdirTemp("", "pdfminion-font-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := font.InstallTrueTypeFont(tmpDir, fontFile); err != nil {
+		return "", fmt.Errorf("%w: font file %s cannot be installed: %v", domain.ErrInvalidConfig, fontFile, err)
+	}
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) != 1 {
+		return "", fmt.Errorf("%w: font file %s contains %d fonts, expected one", domain.ErrInvalidConfig, fontFile, len(entries))
+	}
+
+	installed, err := os.ReadFile(filepath.Join(tmpDir, entries[0].Name()))
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(entries[0].Name(), filepath.Ext(entries[0].Name()))
+
+	fontRegistry.Lock()
+	defer fontRegistry.Unlock()
+
+	if err := registerFont(entries[0].Name(), name, installed); err != nil {
+		if installedFonts.users == 0 {
+			removeFontDir(logger)
+		}
+		return "", fmt.Errorf("error installing font %s: %w", fontFile, err)
+	}
+
+	installedFonts.names[fontFile] = name
+	installedFonts.users++
+	return name, nil
+}
+
+// registerFont writes the installed font into the font directory of this process and registers its metrics.
+// It has to be called with installedFonts and fontRegistry locked.
+func registerFont(fileName, name string, installed []byte) error {
+	dir, err := processFontDir()
+	if err != nil {
+		return err
+	}
+	// fonts of the user's font directory are linked into dir and used as they are
+	installedFile := filepath.Join(dir, fileName)
+	if _, err := os.Stat(installedFile); err != nil {
+		if err := os.WriteFile(installedFile, installed, 0644); err != nil {
+			return err
+		}
+	}
+	if font.IsUserFont(name) {
+		return nil
+	}
+
+	if installed, err = os.ReadFile(installedFile); err != nil {
+		return err
+	}
+	var metrics font.TTFLight
+	if err := gob.NewDecoder(bytes.NewReader(installed)).Decode(&metrics); err != nil {
+		return err
+	}
+	font.UserFontMetrics[name] = metrics
+	installedFonts.registered = append(installedFonts.registered, name)
+	return nil
+}
+
+// releaseFonts removes all installed fonts, once no run uses them any more
+func releaseFonts(logger zerolog.Logger) {
+	installedFonts.Lock()
+	defer installedFonts.Unlock()
+
+	installedFonts.users--
+	if installedFonts.users > 0 {
+		return
+	}
+
+	fontRegistry.Lock()
+	defer fontRegistry.Unlock()
+	removeFontDir(logger)
+}
+
+// removeFontDir unregisters the installed fonts, removes the font directory of this process
+// and restores pdfcpu's previous font directory.
+// It has to be called with installedFonts and fontRegistry locked.
+func removeFontDir(logger zerolog.Logger) {
+	for _, name := range installedFonts.registered {
+		delete(font.UserFontMetrics, name)
+	}
+	if installedFonts.dir != "" {
+		if err := os.RemoveAll(installedFonts.dir); err != nil {
+			logger.Warn().Err(err).Str("dir", installedFonts.dir).Msg("Font directory cannot be removed")
+		}
+		if font.UserFontDir == installedFonts.dir {
+			font.UserFontDir = installedFonts.previousDir
+		}
+	}
+
+	installedFonts.dir = ""
+	installedFonts.previousDir = ""
+	installedFonts.names = make(map[string]string)
+	installedFonts.registered = nil
+}
+
+// processFontDir creates the font directory of this process and makes it pdfcpu's font directory.
+// pdfcpu reads the fonts to embed from there, so the fonts of the previous font directory are linked into it.
+// It has to be called with installedFonts and fontRegistry locked.
+func processFontDir() (string, error) {
+	if installedFonts.dir == "" {
+		dir, err := os.MkdirTemp("", "pdfminion-fonts-")
+		if err != nil {
+			return "", err
+		}
+		installedFonts.dir = dir
+	}
+	if font.UserFontDir == installedFonts.dir {
+		return installedFonts.dir, nil
+	}
+
+	if font.UserFontDir != "" {
+		entries, err := os.ReadDir(font.UserFontDir)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		for _, entry := range entries {
+			link := filepath.Join(installedFonts.dir, entry.Name())
+			if _, err := os.Lstat(link); err == nil || filepath.Ext(entry.Name()) != ".gob" {
+				continue
+			}
+			if err := os.Symlink(filepath.Join(font.UserFontDir, entry.Name()), link); err != nil {
+				return "", err
+			}
+		}
+	}
+	installedFonts.previousDir = font.UserFontDir
+	font.UserFontDir = installedFonts.dir
+	return installedFonts.dir, nil
+}
+
+// stampFont returns the installed font file if configured, builtIn otherwise
+func (p *Processor) stampFont(builtIn string) string {
+	if p.fontName != "" {
+		return p.fontName
+	}
+	return builtIn
+}
@@ -0,0 +1,103 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/gofont/goregular"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+// embeddedFonts returns the names of all subset fonts embedded in fileName
+func embeddedFonts(t *testing.T, fileName string) []string {
+	ctx, err := api.ReadContextFile(fileName)
+	assert.NoError(t, err)
+	assert.NoError(t, api.OptimizeContext(ctx))
+
+	names := make([]string, 0)
+	for _, fontObject := range ctx.Optimize.FontObjects {
+		if fontObject.Prefix != "" {
+			names = append(names, fontObject.FontName)
+		}
+	}
+	return names
+}
+
+// userFontDir points pdfcpu's font directory to an empty directory during the test
+func userFontDir(t *testing.T) string {
+	dir := t.TempDir()
+	previous := font.UserFontDir
+	font.UserFontDir = dir
+	t.Cleanup(func() { font.UserFontDir = previous })
+	return dir
+}
+
+func TestRunWithFontFile(t *testing.T) {
+	userDir := userFontDir(t)
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.FontFile = filepath.Join(t.TempDir(), "Go-Regular.ttf")
+	assert.NoError(t, os.WriteFile(cfg.FontFile, goregular.TTF, 0644))
+	cfg.RunningHeader = "Καλημέρα – Добрый день"
+	cfg.TOC = true
+
+	p := NewProcessor(cfg)
+	result, err := p.Run(context.Background())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, p.fontName)
+	assert.Equal(t, p.fontName, p.stampFont(headerFont))
+	for _, file := range append([]string{result.TOCFile}, result.Files[0].OutputPath) {
+		assert.NoError(t, api.ValidateFile(file, nil), file)
+		assert.Contains(t, embeddedFonts(t, file), p.fontName, file)
+	}
+
+	// the font is installed for this process only
+	entries, err := os.ReadDir(userDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRunWithFontFileLeavesNothingBehind(t *testing.T) {
+	userDir := userFontDir(t)
+	// the font directory of the process is created in TMPDIR
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "OnePDF"
+	cfg.TargetDir = t.TempDir()
+	cfg.FontFile = filepath.Join(t.TempDir(), "Go-Regular.ttf")
+	assert.NoError(t, os.WriteFile(cfg.FontFile, goregular.TTF, 0644))
+	cfg.RunningHeader = "{chapterTitle}"
+
+	p := NewProcessor(cfg)
+	userFonts := font.UserFontNames()
+	_, err := p.Run(context.Background())
+	assert.NoError(t, err)
+	_, err = p.Plan(context.Background())
+	assert.NoError(t, err)
+
+	entries, err := os.ReadDir(tmpDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, userDir, font.UserFontDir)
+	assert.ElementsMatch(t, userFonts, font.UserFontNames())
+}
+
+func TestRunWithInvalidFontFile(t *testing.T) {
+	userFontDir(t)
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = filepath.Join(t.TempDir(), "out")
+	cfg.FontFile = filepath.Join(t.TempDir(), "not-a-font.ttf")
+	writeFile(t, cfg.FontFile, "this is not a TrueType font")
+
+	_, err := NewProcessor(cfg).Run(context.Background())
+	assert.True(t, errors.Is(err, domain.ErrInvalidConfig), err)
+	assert.NoDirExists(t, cfg.TargetDir)
+}
@@ -0,0 +1,86 @@
+package pdf
+
+import (
+	"path/filepath"
+	"pdfminion/internal/domain"
+)
+
+// readHandout returns the handout manifest, or nil if there is none
+func (p *Processor) readHandout() (*domain.Handout, error) {
+	handoutPath := p.config.HandoutPath()
+	if handoutPath == "" {
+		return nil, nil
+	}
+	return domain.ReadHandout(handoutPath)
+}
+
+// HandoutPDFs returns the files of all handout chapters in order.
+// It replaces CollectCandidatePDFs and OrderCandidates if a handout manifest is used.
+func (p *Processor) HandoutPDFs(handout *domain.Handout) []string {
+	files := make([]string, 0, len(handout.Chapters))
+	for _, chapter := range handout.Chapters {
+		files = append(files, filepath.Join(p.config.SourceDir, chapter.File))
+	}
+	return files
+}
+
+// applyHandout attaches the chapter options of the handout to the validated files
+func (p *Processor) applyHandout(handout *domain.Handout, pdfFiles []SingleFileToProcess) {
+	chapters := make(map[string]*domain.HandoutChapter, len(handout.Chapters))
+	for i := range handout.Chapters {
+		chapters[filepath.Join(p.config.SourceDir, handout.Chapters[i].File)] = &handout.Chapters[i]
+	}
+
+	for i := range pdfFiles {
+		chapter := chapters[pdfFiles[i].SourcePath]
+		pdfFiles[i].chapter = chapter
+		if chapter != nil && chapter.Title != "" {
+			pdfFiles[i].ChapterTitle = chapter.Title
+		}
+	}
+}
+
+// evenifies reports whether the EvenifyPolicy applies to file
+func (p *Processor) evenifies(file SingleFileToProcess) bool {
+	if file.chapter != nil && file.chapter.Evenify != nil {
+		return *file.chapter.Evenify
+	}
+	return p.config.Evenify
+}
+
+// runningHeader returns the running header template for page pageNr of file, empty if it has none.
+// The running header of a handout chapter replaces all running headers of the configuration,
+// which are not added to front and back matter.
+func (p *Processor) runningHeader(file SingleFileToProcess, pageNr int) string {
+	if file.chapter != nil && file.chapter.RunningHeader != nil {
+		return *file.chapter.RunningHeader
+	}
+	if file.isMatter() {
+		return ""
+	}
+	return p.config.RunningHeaderFor(pageNr)
+}
+
+// hasRunningHeader reports whether a running header is added to at least one page of file
+func (p *Processor) hasRunningHeader(file SingleFileToProcess) bool {
+	if file.chapter != nil && file.chapter.RunningHeader != nil {
+		return *file.chapter.RunningHeader != ""
+	}
+	if file.isMatter() {
+		return false
+	}
+	return p.config.RunningHeader != "" || p.config.RunningHeaderEven != "" || p.config.RunningHeaderOdd != ""
+}
+
+// isNumbered reports whether the footer with chapter and page number is added to file
+func (file SingleFileToProcess) isNumbered() bool {
+	return file.chapter == nil || file.chapter.IsNumbered()
+}
+
+// nextChapterNr returns the chapter number of file, given the number of the previous chapter
+func (file SingleFileToProcess) nextChapterNr(previousNr int) int {
+	if file.chapter == nil {
+		return previousNr + 1
+	}
+	return domain.NextChapterNr(previousNr, *file.chapter)
+}
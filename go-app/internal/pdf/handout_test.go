@@ -0,0 +1,54 @@
+package pdf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func TestRunWithHandout(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Handout = filepath.Join(t.TempDir(), domain.DefaultHandoutFile)
+	writeFile(t, cfg.Handout, `chapters:
+  - file: sample-A4-portrait-3pgs.pdf
+    title: Basics
+    number: 3
+    evenify: false
+  - file: sample-A4-portrait-1pg.pdf
+    numbering: false
+`)
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, result.Files, 2)
+
+	basics, second := result.Files[0], result.Files[1]
+	assert.Equal(t, "Basics", basics.ChapterTitle)
+	assert.Equal(t, 3, basics.ChapterNr)
+	assert.Equal(t, 0, basics.BlankPagesAdded)
+	assert.Equal(t, 3, basics.LastPageNr)
+
+	assert.Equal(t, 4, second.ChapterNr)
+	assert.Equal(t, 1, second.BlankPagesAdded)
+	assert.Equal(t, 4, second.FirstPageNr)
+	assert.Equal(t, 5, second.LastPageNr)
+}
+
+func TestUnnumberedChapterHasNoFooter(t *testing.T) {
+	numbering, header := false, "Cheat sheet"
+	p := NewProcessor(domain.NewDefaultEnglishConfig())
+	file := SingleFileToProcess{
+		Filename: "cheatsheet.pdf", PageCount: 2, BlankPagesAdded: 1, ChapterNr: 4, FirstPageNr: 4, LastPageNr: 5,
+		chapter: &domain.HandoutChapter{File: "cheatsheet.pdf", Numbering: &numbering, RunningHeader: &header},
+	}
+
+	wmcs, err := p.textWatermarksForFile(file, 5)
+	assert.NoError(t, err)
+	// running header on both pages, blank page text on the second one
+	assert.Len(t, wmcs[1], 1)
+	assert.Len(t, wmcs[2], 2)
+}
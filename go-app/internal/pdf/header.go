@@ -0,0 +1,50 @@
+package pdf
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"pdfminion/internal/util"
+)
+
+const (
+	// built-in font, replaced by the font file if configured
+	headerFont    = "Helvetica"
+	fontColorSize = "points:16, scale: 0.9 abs, rot: 0, color: 0.5 0.5 0.5"
+)
+
+// create a map[int] of TextWatermark configurations for the running header,
+// texts are the rendered headers of all pages, pages with an empty header are left out
+func headerConfigurationForFile(fontName string, texts []string, previousPageNr int) map[int]*model.Watermark {
+
+	wmcs := make(map[int]*model.Watermark)
+
+	for i, text := range texts {
+		if text == "" {
+			continue
+		}
+		page := i + 1
+		wmcs[page], _ = api.TextWatermark(text,
+			headerDescription(fontName, previousPageNr+page), true, false, types.POINTS)
+	}
+	return wmcs
+}
+
+// creates a pdfcpu TextWatermark description for the running header,
+// mirrored to the outer edge like the footer: left on even pages, right on odd pages
+func headerDescription(fontName string, pageNumber int) string {
+
+	const evenPos string = "position: tl"
+	const evenOffset string = "offset: 20 -6"
+	const oddPos string = "position: tr"
+	const oddOffset string = "offset: -20 -6"
+
+	positionAndOffset := ""
+
+	if util.IsEven(pageNumber) {
+		positionAndOffset = evenPos + "," + evenOffset
+	} else {
+		positionAndOffset = oddPos + "," + oddOffset
+	}
+	return "font:" + fontName + ", " + fontColorSize + "," + positionAndOffset
+}
@@ -0,0 +1,28 @@
+package pdf
+
+import (
+	"github.com/stretchr/testify/assert"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func TestHeaderIsMirroredToOuterEdge(t *testing.T) {
+	assert.Contains(t, headerDescription(headerFont, 2), "position: tl")
+	assert.Contains(t, headerDescription(headerFont, 3), "position: tr")
+}
+
+func TestRunningHeadersForEvenAndOddPages(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.RunningHeaderEven = "{chapterTitle}"
+	p := NewProcessor(cfg)
+	file := SingleFileToProcess{ChapterNr: 2, ChapterTitle: "Basics", PageCount: 3, FirstPageNr: 4, LastPageNr: 6}
+
+	wmcs, err := p.textWatermarksForFile(file, 6)
+	assert.NoError(t, err)
+	// footers on all pages, the header only on the even pages 4 and 6
+	assert.Len(t, wmcs[1], 2)
+	assert.Len(t, wmcs[2], 1)
+	assert.Len(t, wmcs[3], 2)
+	assert.Equal(t, "Basics", wmcs[1][1].TextString)
+	assert.Empty(t, p.runningHeader(file, 5))
+}
@@ -0,0 +1,229 @@
+package pdf
+
+import (
+	"fmt"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+)
+
+// pageLabelRange is a single entry of the /PageLabels number tree:
+// starting at page index (0-based) pages are labeled with prefix and style, counting from start
+type pageLabelRange struct {
+	pageIndex int
+	style     string // "D" decimal, "r" lowercase roman, see pageLabelStyles
+	start     int
+	prefix    string // e.g. "3." for chapter-relative page numbers, may be empty
+}
+
+// pageLabelStyles maps number styles to the styles of PDF page labels
+var pageLabelStyles = map[string]string{
+	domain.NumberStyleArabic:     "D",
+	domain.NumberStyleLowerRoman: "r",
+	domain.NumberStyleUpperRoman: "R",
+	domain.NumberStyleLowerAlpha: "a",
+	domain.NumberStyleUpperAlpha: "A",
+}
+
+// addChapterPageLabels writes native PDF page labels and a chapter bookmark into a single file,
+// so that PDF viewers show the same page numbers as the stamped footer.
+// An existing outline is kept below the chapter bookmark.
+func (p *Processor) addChapterPageLabels(ctx *model.Context, file SingleFileToProcess) error {
+	labels := []pageLabelRange{p.labelForChapter(0, file)}
+	bookmark := pdfcpu.Bookmark{Title: p.chapterBookmarkTitle(file), PageFrom: 1 + file.BlankPagesBefore}
+
+	return p.setPageLabelsAndOutline(ctx, labels, []pdfcpu.Bookmark{bookmark}, true)
+}
+
+// addPageLabelsToMergedFile writes page labels and one bookmark per chapter into the merged file.
+// Pages of the table of contents (if any) follow the front matter and are labeled with roman numbers,
+// continuing the page numbers of the front matter.
+func (p *Processor) addPageLabelsToMergedFile(mergedFile string, tocPageCount, nrOfValidPDFs int, pdfFiles []SingleFileToProcess) error {
+	labels := make([]pageLabelRange, 0, nrOfValidPDFs+1)
+	bookmarks := make([]pdfcpu.Bookmark, 0, nrOfValidPDFs+1)
+
+	frontMatter := frontMatterCount(nrOfValidPDFs, pdfFiles)
+	pageIndex := 0
+	for i := 0; i <= nrOfValidPDFs; i++ {
+		if i == frontMatter && tocPageCount > 0 {
+			labels = append(labels, pageLabelRange{pageIndex: pageIndex, style: "r", start: pageIndex + 1})
+			bookmarks = append(bookmarks, pdfcpu.Bookmark{Title: p.config.TOCTitle, PageFrom: pageIndex + 1})
+			pageIndex += tocPageCount
+		}
+		if i == nrOfValidPDFs {
+			break
+		}
+		blankPages := pdfFiles[i].mergedBlankPagesBefore
+		label := p.labelForChapter(pageIndex+blankPages, pdfFiles[i])
+		// blank pages inserted before the chapter are numbered like the chapter, unless its numbering restarts
+		if blankPages > 0 && label.start > blankPages {
+			label.pageIndex -= blankPages
+			label.start -= blankPages
+		}
+		labels = append(labels, label)
+		bookmarks = append(bookmarks, pdfcpu.Bookmark{Title: p.chapterBookmarkTitle(pdfFiles[i]),
+			PageFrom: pageIndex + blankPages + pdfFiles[i].BlankPagesBefore + 1})
+		pageIndex += blankPages + pdfFiles[i].PageCount
+	}
+
+	// outlines of the source files cannot be kept for merged files, as they do not survive merging
+	return p.writePageLabelsAndOutline(mergedFile, labels, bookmarks)
+}
+
+// labelForChapter returns the page label range of a chapter starting at pageIndex,
+// labeled like the page numbers shown in the footer
+func (p *Processor) labelForChapter(pageIndex int, file SingleFileToProcess) pageLabelRange {
+	label := pageLabelRange{pageIndex: pageIndex, style: pageLabelStyles[p.config.PageNumberStyle], start: file.FirstPageNr}
+	if label.style == "" {
+		label.style = "D"
+	}
+	switch {
+	case file.isMatter():
+		label.style = "r"
+	case p.footerShowsChapterPage():
+		label.start = 1
+	case p.config.PageNumbering == domain.PageNumberingChapter:
+		label.start = 1
+		label.prefix = p.config.ChapterPagePrefix(file.ChapterNr)
+	}
+	return label
+}
+
+// footerShowsChapterPage reports whether the footer shows the page number within the chapter ({chapterPage})
+// rather than the page number within the handout ({page}), e.g. with the default footer of PageCountScopeChapter
+func (p *Processor) footerShowsChapterPage() bool {
+	templates := []string{p.config.FooterTemplateFor(1), p.config.FooterTemplateFor(2)}
+	for _, template := range templates {
+		if domain.UsesPlaceholder(template, domain.PlaceholderPage) {
+			return false
+		}
+	}
+	for _, template := range templates {
+		if domain.UsesPlaceholder(template, domain.PlaceholderChapterPage) {
+			return true
+		}
+	}
+	return false
+}
+
+// chapterBookmarkTitle renders e.g. "Chapter 3: error handling", or just the title of front and back matter
+func (p *Processor) chapterBookmarkTitle(file SingleFileToProcess) string {
+	if file.isMatter() {
+		return file.ChapterTitle
+	}
+	return p.config.ChapterPrefix + " " + p.config.FormatChapterNr(file.ChapterNr) + ": " + file.ChapterTitle
+}
+
+// writePageLabelsAndOutline replaces page labels and outline of fileName
+func (p *Processor) writePageLabelsAndOutline(fileName string, labels []pageLabelRange, bookmarks []pdfcpu.Bookmark) error {
+	ctx, err := p.readContextFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	if err := p.setPageLabelsAndOutline(ctx, labels, bookmarks, false); err != nil {
+		return err
+	}
+
+	return writeContextFile(ctx, fileName)
+}
+
+// setPageLabelsAndOutline replaces page labels and outline of ctx.
+// If keepExistingOutline is set, an existing outline is nested below the first bookmark.
+func (p *Processor) setPageLabelsAndOutline(ctx *model.Context, labels []pageLabelRange, bookmarks []pdfcpu.Bookmark, keepExistingOutline bool) error {
+	if err := setPageLabels(ctx, labels); err != nil {
+		return fmt.Errorf("error setting page labels: %w", err)
+	}
+
+	if err := p.setOutline(ctx, bookmarks, keepExistingOutline); err != nil {
+		return fmt.Errorf("error setting outline: %w", err)
+	}
+	return nil
+}
+
+func setPageLabels(ctx *model.Context, labels []pageLabelRange) error {
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	nums := types.Array{}
+	for _, label := range labels {
+		d := types.Dict(map[string]types.Object{
+			"S":  types.Name(label.style),
+			"St": types.Integer(label.start),
+		})
+		if label.prefix != "" {
+			prefix, err := types.EscapeUTF16String(label.prefix)
+			if err != nil {
+				return err
+			}
+			d["P"] = types.StringLiteral(*prefix)
+		}
+		nums = append(nums, types.Integer(label.pageIndex), d)
+	}
+
+	ir, err := ctx.IndRefForNewObject(types.Dict(map[string]types.Object{"Nums": nums}))
+	if err != nil {
+		return err
+	}
+	rootDict["PageLabels"] = *ir
+	return nil
+}
+
+func (p *Processor) setOutline(ctx *model.Context, bookmarks []pdfcpu.Bookmark, keepExistingOutline bool) error {
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := rootDict.Find("Outlines"); ok {
+		if keepExistingOutline && len(bookmarks) > 0 {
+			existing, err := pdfcpu.BookmarksForOutline(ctx)
+			if err != nil {
+				p.logger.Debug().Err(err).Msg("Existing outline cannot be read and is replaced")
+			} else {
+				bookmarks[0].Children = existing
+			}
+		}
+		rootDict.Delete("Outlines")
+	}
+
+	return pdfcpu.AddBookmarks(ctx, bookmarks)
+}
+
+// readContextFile reads and validates fileName with the relaxed configuration
+func (p *Processor) readContextFile(fileName string) (*model.Context, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ctx, err := api.ReadContext(f, p.relaxedConf)
+	if err != nil {
+		return nil, err
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// writeContextFile writes ctx to a temporary file first, which then replaces fileName
+func writeContextFile(ctx *model.Context, fileName string) error {
+	// files from sub-folders of the source directory are written to the same sub-folders
+	if err := os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+		return err
+	}
+	tmpFile := fileName + ".tmp"
+	if err := api.WriteContextFile(ctx, tmpFile); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	return os.Rename(tmpFile, fileName)
+}
@@ -0,0 +1,122 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+// pageLabels returns the page label ranges of fileName, like ["0 r 1", "2 D 1", "4 D 1 3."]
+func pageLabels(t *testing.T, fileName string) []string {
+	ctx, err := NewProcessor(domain.NewDefaultEnglishConfig()).readContextFile(fileName)
+	assert.NoError(t, err)
+
+	rootDict, err := ctx.Catalog()
+	assert.NoError(t, err)
+	labels, err := ctx.DereferenceDict(rootDict["PageLabels"])
+	assert.NoError(t, err)
+
+	nums := labels.ArrayEntry("Nums")
+	result := make([]string, 0)
+	for i := 0; i+1 < len(nums); i += 2 {
+		d := nums[i+1].(types.Dict)
+		label := fmt.Sprintf("%d %s %d", nums[i].(types.Integer).Value(), *d.NameEntry("S"), *d.IntEntry("St"))
+		if prefix := d.StringLiteralEntry("P"); prefix != nil {
+			p, err := types.StringLiteralToString(*prefix)
+			assert.NoError(t, err)
+			label += " " + p
+		}
+		result = append(result, label)
+	}
+	return result
+}
+
+func TestPageLabelsAndOutlines(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Merge = true
+	cfg.TOC = true
+
+	assert.NoError(t, ProcessPDFs(context.Background(), &cfg))
+
+	// second chapter starts at page 3, as the first one has been evenified
+	secondChapter := filepath.Join(cfg.TargetDir, "sample-A4-portrait-3pgs.pdf")
+	assert.Equal(t, []string{"0 D 3"}, pageLabels(t, secondChapter))
+
+	// second chapter starts after toc (2 pages) and first chapter (2 pages)
+	merged := filepath.Join(cfg.TargetDir, cfg.MergeFileName)
+	assert.Equal(t, []string{"0 r 1", "2 D 1", "4 D 3"}, pageLabels(t, merged))
+
+	ctx, err := NewProcessor(domain.NewDefaultEnglishConfig()).readContextFile(merged)
+	assert.NoError(t, err)
+	bookmarks, err := pdfcpu.BookmarksForOutline(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, bookmarks, 3)
+	assert.Equal(t, "Chapter 2: sample A4 portrait 3pgs", bookmarks[2].Title)
+	assert.Equal(t, 5, bookmarks[2].PageFrom)
+}
+
+func TestPageLabelsWithChapterRelativeNumbers(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Merge = true
+	cfg.ChapterNumberStyle = domain.NumberStyleUpperAlpha
+	cfg.PageNumbering = domain.PageNumberingChapter
+	cfg.ChapterPageSeparator = "-"
+
+	assert.NoError(t, ProcessPDFs(context.Background(), &cfg))
+
+	merged := filepath.Join(cfg.TargetDir, cfg.MergeFileName)
+	assert.Equal(t, []string{"0 D 1 A-", "2 D 1 B-"}, pageLabels(t, merged))
+
+	ctx, err := NewProcessor(cfg).readContextFile(merged)
+	assert.NoError(t, err)
+	bookmarks, err := pdfcpu.BookmarksForOutline(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "Chapter B: sample A4 portrait 3pgs", bookmarks[1].Title)
+}
+
+func TestPageLabelsWithRomanPageNumbers(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.PageNumberStyle = domain.NumberStyleLowerRoman
+	p := NewProcessor(cfg)
+
+	label := p.labelForChapter(4, SingleFileToProcess{ChapterNr: 2, FirstPageNr: 5})
+	assert.Equal(t, pageLabelRange{pageIndex: 4, style: "r", start: 5}, label)
+}
+
+func TestPageLabelsWithLetterPageNumbersAfterZ(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.PageNumberStyle = domain.NumberStyleLowerAlpha
+	p := NewProcessor(cfg)
+	file := SingleFileToProcess{ChapterNr: 2, FirstPageNr: 27}
+
+	// PDF viewers show the 27th page of a lower-alpha label range as "aa", like the footer
+	assert.Equal(t, pageLabelRange{pageIndex: 0, style: "a", start: 27}, p.labelForChapter(0, file))
+	assert.Equal(t, "aa", p.config.FormatPageNr(file.ChapterNr, 1, file.FirstPageNr))
+}
+
+func TestPageLabelsFollowFooterWithChapterScope(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Merge = true
+	cfg.PageCountScope = domain.PageCountScopeChapter
+
+	// the default footer shows {chapterPage} of {chapterPages}
+	assert.NoError(t, ProcessPDFs(context.Background(), &cfg))
+	assert.Equal(t, []string{"0 D 1", "2 D 1"}, pageLabels(t, filepath.Join(cfg.TargetDir, cfg.MergeFileName)))
+
+	// {page} is numbered through the handout, the scope only applies to {total}
+	cfg.TargetDir = t.TempDir()
+	cfg.FooterTemplate = "Page {page} of {total}"
+	assert.NoError(t, ProcessPDFs(context.Background(), &cfg))
+	assert.Equal(t, []string{"0 D 1", "2 D 3"}, pageLabels(t, filepath.Join(cfg.TargetDir, cfg.MergeFileName)))
+}
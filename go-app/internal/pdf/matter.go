@@ -0,0 +1,61 @@
+package pdf
+
+import (
+	"pdfminion/internal/domain"
+	"sort"
+)
+
+// assignMatter marks front and back matter, as given by the handout or the FrontMatter and BackMatter patterns,
+// and moves front matter before and back matter after all chapters, keeping their order otherwise
+func (p *Processor) assignMatter(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) {
+	for i := 0; i < nrOfValidPDFs; i++ {
+		relPath := p.relativeToSource(pdfFiles[i].SourcePath)
+		switch {
+		case pdfFiles[i].chapter != nil && pdfFiles[i].chapter.Matter != "":
+			pdfFiles[i].Matter = pdfFiles[i].chapter.Matter
+		case matchesAny(p.config.FrontMatter, relPath):
+			pdfFiles[i].Matter = domain.MatterFront
+		case matchesAny(p.config.BackMatter, relPath):
+			pdfFiles[i].Matter = domain.MatterBack
+		}
+	}
+
+	sort.SliceStable(pdfFiles[:nrOfValidPDFs], func(i, j int) bool {
+		return matterRank(pdfFiles[i].Matter) < matterRank(pdfFiles[j].Matter)
+	})
+}
+
+func matterRank(matter string) int {
+	switch matter {
+	case domain.MatterFront:
+		return 0
+	case domain.MatterBack:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// isMatter reports whether file is front or back matter, which has no chapter number
+// and is numbered separately from the chapters, see MatterNumbering
+func (file SingleFileToProcess) isMatter() bool {
+	return file.Matter != ""
+}
+
+// backMatterCount returns the number of back matter files, which are the last files once assignMatter has been called
+func backMatterCount(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) int {
+	count := 0
+	for count < nrOfValidPDFs && pdfFiles[nrOfValidPDFs-1-count].Matter == domain.MatterBack {
+		count++
+	}
+	return count
+}
+
+// frontMatterCount returns the number of front matter files, which are the first files once assignMatter has been called
+func frontMatterCount(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) int {
+	count := 0
+	for count < nrOfValidPDFs && pdfFiles[count].Matter == domain.MatterFront {
+		count++
+	}
+	return count
+}
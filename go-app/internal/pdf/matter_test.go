@@ -0,0 +1,99 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func TestPlanWithBackMatterPattern(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.BackMatter = []string{"*1pg.pdf"}
+
+	plan, err := NewProcessor(cfg).Plan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 6, plan.TotalPageCount)
+	assert.Equal(t, []PlannedChapter{
+		{
+			SourcePath: filepath.Join(cfg.SourceDir, "sample-A4-portrait-3pgs.pdf"), File: "sample-A4-portrait-3pgs.pdf",
+			ChapterNr: 1, ChapterLabel: "1", ChapterTitle: "sample A4 portrait 3pgs", OriginalPageCount: 3, BlankPagesAdded: 1,
+			FirstPageNr: 1, LastPageNr: 4, Footer: "Chapter 1 - Page 1 of 4",
+		},
+		{
+			SourcePath: filepath.Join(cfg.SourceDir, "sample-A4-portrait-1pg.pdf"), File: "sample-A4-portrait-1pg.pdf",
+			Matter: domain.MatterBack, ChapterTitle: "sample A4 portrait 1pg", OriginalPageCount: 1, BlankPagesAdded: 1,
+			FirstPageNr: 1, LastPageNr: 2,
+		},
+	}, plan.Chapters)
+
+	// back matter is not counted as a chapter
+	var out bytes.Buffer
+	PrintPlan(&out, plan)
+	assert.Contains(t, out.String(), "1 chapters in 2 files, 6 pages")
+}
+
+func TestMatterFooterWithRomanNumbers(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.RunningHeader = "{chapterTitle}"
+	file := SingleFileToProcess{
+		Filename: "legal.pdf", ChapterTitle: "Legal notice", Matter: domain.MatterBack, PageCount: 2, FirstPageNr: 3, LastPageNr: 4,
+	}
+
+	assert.Equal(t, "", NewProcessor(cfg).footerText(file, 1, 8))
+	assert.False(t, NewProcessor(cfg).hasRunningHeader(file))
+
+	cfg.MatterNumbering = domain.MatterNumberingRoman
+	assert.Equal(t, "iv", NewProcessor(cfg).footerText(file, 2, 8))
+}
+
+func TestRunWithFrontAndBackMatter(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir
+	cfg.TargetDir = t.TempDir()
+	cfg.Merge = true
+	cfg.TOC = true
+	cfg.MatterNumbering = domain.MatterNumberingRoman
+	cfg.Handout = filepath.Join(t.TempDir(), domain.DefaultHandoutFile)
+	writeFile(t, cfg.Handout, `chapters:
+  - file: sample-A4-portrait-1pg.pdf
+    title: Cover
+    matter: front
+  - file: sample-A4-portrait-3pgs.pdf
+  - file: sample-A4-portrait-4pgs.pdf
+  - file: OnePDF/sample-A4-portrait-1pg.pdf
+    title: Legal notice
+    matter: back
+`)
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 12, result.TotalPageCount)
+
+	chapterNrs := make([]int, 0, len(result.Files))
+	for _, file := range result.Files {
+		chapterNrs = append(chapterNrs, file.ChapterNr)
+	}
+	assert.Equal(t, []int{0, 1, 2, 0}, chapterNrs)
+	assert.Equal(t, domain.MatterBack, result.Files[3].Matter)
+	// the legal notice continues after cover (i-ii) and table of contents (iii-iv)
+	assert.Equal(t, 5, result.Files[3].FirstPageNr)
+
+	// cover, table of contents, two chapters, legal notice
+	assert.Equal(t, []string{"0 r 1", "2 r 3", "4 D 1", "8 D 5", "12 r 5"}, pageLabels(t, result.MergedFile))
+
+	ctx, err := NewProcessor(cfg).readContextFile(result.MergedFile)
+	assert.NoError(t, err)
+	bookmarks, err := pdfcpu.BookmarksForOutline(ctx)
+	assert.NoError(t, err)
+	titles := make([]string, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		titles = append(titles, bookmark.Title)
+	}
+	assert.Equal(t, []string{"Cover", "Table of Contents", "Chapter 1: sample A4 portrait 3pgs",
+		"Chapter 2: sample A4 portrait 4pgs", "Legal notice"}, titles)
+}
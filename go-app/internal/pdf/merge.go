@@ -0,0 +1,84 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"path/filepath"
+)
+
+// MergeAllFiles joins the processed (evenified and numbered) files in chapter order
+// into a single PDF named MergeFileName within the output directory.
+// Blank pages added by Evenify are kept, so duplex printing stays aligned.
+// With EvenifyScope "merged", the blank pages of the EvenifyPolicy are added to the merged file only.
+// If tocFile is given, the table of contents is inserted after the front matter (if any).
+// It returns the path of the merged file and the number of blank pages appended to it.
+func (p *Processor) MergeAllFiles(ctx context.Context, tocFile string, nrOfValidPDFs int, pdfFiles []SingleFileToProcess) (string, int, error) {
+	if err := ctx.Err(); err != nil {
+		return "", 0, err
+	}
+
+	var err error
+	tocPageCount := 0
+	if tocFile != "" {
+		if tocPageCount, err = api.PageCountFile(tocFile); err != nil {
+			return "", 0, fmt.Errorf("error counting pages of %s: %w", tocFile, err)
+		}
+	}
+
+	frontMatter := frontMatterCount(nrOfValidPDFs, pdfFiles)
+	inFiles := make([]string, 0, nrOfValidPDFs+2)
+	pageCount := 0
+	for i := 0; i < nrOfValidPDFs; i++ {
+		if i == frontMatter && tocFile != "" {
+			inFiles = append(inFiles, tocFile)
+			pageCount += tocPageCount
+		}
+		if blankPages := pdfFiles[i].mergedBlankPagesBefore; blankPages > 0 {
+			blankFile, err := p.blankPagesFile(blankPages)
+			if err != nil {
+				return "", 0, err
+			}
+			inFiles = append(inFiles, blankFile)
+			pageCount += blankPages
+		}
+		inFiles = append(inFiles, pdfFiles[i].Filename)
+		pageCount += pdfFiles[i].PageCount
+	}
+	if frontMatter == nrOfValidPDFs && tocFile != "" {
+		inFiles = append(inFiles, tocFile)
+		pageCount += tocPageCount
+	}
+	appendedBlankPages := 0
+	if p.config.Evenify && !p.blankPagesInFiles() {
+		if appendedBlankPages = p.blankPagesToAppend(pageCount); appendedBlankPages > 0 {
+			blankFile, err := p.blankPagesFile(appendedBlankPages)
+			if err != nil {
+				return "", 0, err
+			}
+			inFiles = append(inFiles, blankFile)
+		}
+	}
+
+	mergedFile := filepath.Join(p.outputDir, p.config.MergeFileName)
+	p.logger.Debug().Str("file", mergedFile).Int("fileCount", len(inFiles)).Msg("Merging files")
+
+	if err := api.MergeCreateFile(inFiles, mergedFile, p.relaxedConf); err != nil {
+		return "", 0, fmt.Errorf("error merging files into %s: %w", mergedFile, err)
+	}
+
+	if err := p.addPageLabelsToMergedFile(mergedFile, tocPageCount, nrOfValidPDFs, pdfFiles); err != nil {
+		return "", 0, fmt.Errorf("error adding page labels to %s: %w", mergedFile, err)
+	}
+
+	if pageCount, err = api.PageCountFile(mergedFile); err != nil {
+		return "", 0, fmt.Errorf("error counting pages of %s: %w", mergedFile, err)
+	}
+
+	fileCount := nrOfValidPDFs
+	if tocFile != "" {
+		fileCount++
+	}
+	fmt.Fprintf(p.out, "Merged %d files into %s (%d pages)\n", fileCount, filepath.Base(mergedFile), pageCount)
+	return mergedFile, appendedBlankPages, nil
+}
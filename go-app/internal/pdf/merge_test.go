@@ -0,0 +1,44 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+// sampleDir is relative to this package, see sample_pdfs for the files used
+const sampleDir = "../../sample-files-for-testing/"
+
+func TestMergeKeepsBlankPages(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Merge = true
+
+	assert.NoError(t, ProcessPDFs(context.Background(), &cfg))
+
+	// 1 page + 3 pages, both evenified: 2 + 4 pages
+	pageCount, err := api.PageCountFile(filepath.Join(cfg.TargetDir, cfg.MergeFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, pageCount)
+}
+
+func TestMergedFileMustNotOverwriteChapter(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = t.TempDir()
+	cfg.TargetDir = filepath.Join(t.TempDir(), "out")
+	cfg.Merge = true
+	sample, err := os.ReadFile(sampleDir + "sample-A4-portrait-1pg.pdf")
+	assert.NoError(t, err)
+	writeFile(t, filepath.Join(cfg.SourceDir, "intro.pdf"), string(sample))
+	writeFile(t, filepath.Join(cfg.SourceDir, cfg.MergeFileName), string(sample))
+
+	_, err = NewProcessor(cfg).Run(context.Background())
+	assert.True(t, errors.Is(err, domain.ErrInvalidConfig), err)
+	assert.NoDirExists(t, cfg.TargetDir)
+}
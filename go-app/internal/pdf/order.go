@@ -0,0 +1,172 @@
+package pdf
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"sort"
+	"strings"
+)
+
+// OrderCandidates sorts the candidate files into chapter order, as configured by Order.
+// With OrderManifest, only files listed in the manifest are kept,
+// all other candidates are returned as skipped files.
+func (p *Processor) OrderCandidates(files []string) ([]string, []SkippedFile, error) {
+	switch p.config.Order {
+	case domain.OrderLexical:
+		sort.Strings(files)
+	case domain.OrderMtime:
+		if err := sortByModificationTime(files); err != nil {
+			return nil, nil, err
+		}
+	case domain.OrderManifest:
+		return p.orderByManifest(files)
+	default:
+		sort.SliceStable(files, func(i, j int) bool {
+			return naturalLess(files[i], files[j])
+		})
+	}
+	return files, nil, nil
+}
+
+// sortByModificationTime sorts files oldest first, files with equal times in natural order
+func sortByModificationTime(files []string) error {
+	modTimes := make(map[string]int64, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("error reading modification time of %s: %w", file, err)
+		}
+		modTimes[file] = info.ModTime().UnixNano()
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if modTimes[files[i]] != modTimes[files[j]] {
+			return modTimes[files[i]] < modTimes[files[j]]
+		}
+		return naturalLess(files[i], files[j])
+	})
+	return nil
+}
+
+// orderByManifest returns the candidates in the order of the manifest
+func (p *Processor) orderByManifest(files []string) ([]string, []SkippedFile, error) {
+	manifest := p.config.ManifestPath()
+	entries, err := readManifest(manifest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: error reading manifest %s: %v", domain.ErrInvalidConfig, manifest, err)
+	}
+
+	// candidates by their path relative to the source directory
+	candidates := make(map[string]string, len(files))
+	for _, file := range files {
+		candidates[p.relativeToSource(file)] = file
+	}
+
+	ordered := make([]string, 0, len(entries))
+	listed := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		entry = filepath.Clean(filepath.FromSlash(entry))
+		file, exists := candidates[entry]
+		if !exists {
+			return nil, nil, fmt.Errorf("%w: %q listed in manifest %s is not a PDF file in %s",
+				domain.ErrInvalidConfig, entry, manifest, p.config.SourceDir)
+		}
+		if listed[entry] {
+			return nil, nil, fmt.Errorf("%w: %q is listed more than once in manifest %s", domain.ErrInvalidConfig, entry, manifest)
+		}
+		listed[entry] = true
+		ordered = append(ordered, file)
+	}
+
+	skipped := make([]SkippedFile, 0)
+	for _, file := range files {
+		if !listed[p.relativeToSource(file)] {
+			skipped = append(skipped, SkippedFile{Filename: file, Reason: "not listed in manifest " + manifest})
+		}
+	}
+	return ordered, skipped, nil
+}
+
+// relativeToSource returns the path of file relative to the source directory
+func (p *Processor) relativeToSource(file string) string {
+	relPath, err := filepath.Rel(p.config.SourceDir, file)
+	if err != nil {
+		return file
+	}
+	return relPath
+}
+
+// readManifest returns the chapter files listed in a manifest.
+// YAML manifests (.yaml or .yml) contain a list named "chapters",
+// all other manifests are text files with one file per line, lines starting with # are ignored.
+func readManifest(manifest string) ([]string, error) {
+	switch strings.ToLower(filepath.Ext(manifest)) {
+	case ".yaml", ".yml":
+		v := viper.New()
+		v.SetConfigFile(manifest)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		return v.GetStringSlice("chapters"), nil
+	}
+
+	f, err := os.Open(manifest)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, scanner.Err()
+}
+
+// naturalLess compares a and b like humans do: sequences of digits are compared by their numeric value,
+// so "2-basics.pdf" comes before "10-intro.pdf". Equal values like "2" and "02" are ordered lexically.
+func naturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			startA, startB := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			numA := strings.TrimLeft(a[startA:i], "0")
+			numB := strings.TrimLeft(b[startB:j], "0")
+			if len(numA) != len(numB) {
+				return len(numA) < len(numB)
+			}
+			if numA != numB {
+				return numA < numB
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			return a[i] < b[j]
+		}
+		i++
+		j++
+	}
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+	return a < b
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,116 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2-basics.pdf", "10-intro.pdf", true},
+		{"10-intro.pdf", "2-basics.pdf", false},
+		{"02-basics.pdf", "10-intro.pdf", true},
+		{"chapter2.pdf", "chapter10.pdf", true},
+		{"part1/ch10.pdf", "part2/ch1.pdf", true},
+		{"a.pdf", "b.pdf", true},
+		{"ch1.pdf", "ch1a.pdf", true},
+		{"02.pdf", "2.pdf", true},
+		{"2.pdf", "02.pdf", false},
+		{"same.pdf", "same.pdf", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, naturalLess(tt.a, tt.b), "%s < %s", tt.a, tt.b)
+	}
+}
+
+// orderedNames orders the given files of sourceDir and returns their relative paths
+func orderedNames(t *testing.T, cfg domain.MinionConfig, names ...string) ([]string, []SkippedFile, error) {
+	t.Helper()
+	files := make([]string, 0, len(names))
+	for _, name := range names {
+		files = append(files, filepath.Join(cfg.SourceDir, name))
+	}
+	ordered, skipped, err := NewProcessor(cfg).OrderCandidates(files)
+	return relPaths(t, cfg.SourceDir, ordered), skipped, err
+}
+
+func TestOrderCandidates(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = t.TempDir()
+	names := []string{"10-intro.pdf", "2-basics.pdf", "1-welcome.pdf"}
+	createFiles(t, cfg.SourceDir, names...)
+
+	ordered, _, err := orderedNames(t, cfg, names...)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1-welcome.pdf", "2-basics.pdf", "10-intro.pdf"}, ordered)
+
+	cfg.Order = domain.OrderLexical
+	ordered, _, err = orderedNames(t, cfg, names...)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1-welcome.pdf", "10-intro.pdf", "2-basics.pdf"}, ordered)
+
+	cfg.Order = domain.OrderMtime
+	now := time.Now()
+	for i, name := range []string{"2-basics.pdf", "10-intro.pdf", "1-welcome.pdf"} {
+		modTime := now.Add(time.Duration(i) * time.Minute)
+		assert.NoError(t, os.Chtimes(filepath.Join(cfg.SourceDir, name), modTime, modTime))
+	}
+	ordered, _, err = orderedNames(t, cfg, names...)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2-basics.pdf", "10-intro.pdf", "1-welcome.pdf"}, ordered)
+}
+
+func TestOrderCandidatesByManifest(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = t.TempDir()
+	cfg.Order = domain.OrderManifest
+	names := []string{"intro.pdf", "basics.pdf", "part2/advanced.pdf", "draft.pdf"}
+	createFiles(t, cfg.SourceDir, names...)
+
+	writeFile(t, filepath.Join(cfg.SourceDir, domain.DefaultManifestFile), "# chapters in order\nintro.pdf\n\npart2/advanced.pdf\nbasics.pdf\n")
+	ordered, skipped, err := orderedNames(t, cfg, names...)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"intro.pdf", "part2/advanced.pdf", "basics.pdf"}, ordered)
+	assert.Len(t, skipped, 1)
+	assert.Equal(t, filepath.Join(cfg.SourceDir, "draft.pdf"), skipped[0].Filename)
+
+	cfg.Manifest = filepath.Join(t.TempDir(), "course.yaml")
+	writeFile(t, cfg.Manifest, "chapters:\n  - basics.pdf\n  - intro.pdf\n")
+	ordered, skipped, err = orderedNames(t, cfg, names...)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"basics.pdf", "intro.pdf"}, ordered)
+	assert.Len(t, skipped, 2)
+
+	writeFile(t, cfg.Manifest, "chapters:\n  - basics.pdf\n  - missing.pdf\n")
+	_, _, err = orderedNames(t, cfg, names...)
+	assert.True(t, errors.Is(err, domain.ErrInvalidConfig))
+
+	writeFile(t, cfg.Manifest, "chapters:\n  - basics.pdf\n  - basics.pdf\n")
+	_, _, err = orderedNames(t, cfg, names...)
+	assert.True(t, errors.Is(err, domain.ErrInvalidConfig))
+}
+
+func TestRunInManifestOrder(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Order = domain.OrderManifest
+	cfg.Manifest = filepath.Join(t.TempDir(), "chapters.txt")
+	writeFile(t, cfg.Manifest, "sample-A4-portrait-3pgs.pdf\nsample-A4-portrait-1pg.pdf\n")
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, result.Files, 2)
+	assert.Equal(t, "sample-A4-portrait-3pgs.pdf", filepath.Base(result.Files[0].SourcePath))
+	assert.Equal(t, 1, result.Files[0].ChapterNr)
+	assert.Equal(t, 4, result.Files[0].LastPageNr)
+}
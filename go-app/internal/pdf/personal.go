@@ -0,0 +1,120 @@
+package pdf
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"math/rand"
+	"os"
+	"pdfminion/internal/util"
+)
+
+// mascotImage is the PDFminion mascot, used unless the user provides another image
+//
+//go:embed assets/minion-mascot.png
+var mascotImage []byte
+
+// the mascot is kept small and placed in the outer margin, vertically centered
+const (
+	evenMascotDescription = "position: l, offset: 6 0, scale: 0.07 rel, rot: 0, opacity: 0.9"
+	oddMascotDescription  = "position: r, offset: -6 0, scale: 0.07 rel, rot: 0, opacity: 0.9"
+)
+
+// personalTouch holds the image and the (continuous) page numbers to be decorated
+type personalTouch struct {
+	image []byte
+	pages map[int]bool
+}
+
+// newPersonalTouch selects a random subset of all pages for the mascot image.
+// The pages are selected with a seeded random generator, so reruns with the same seed
+// and the same files give identical output.
+func (p *Processor) newPersonalTouch(lastPageNr int) (*personalTouch, error) {
+	image, err := p.personalTouchImage()
+	if err != nil {
+		return nil, err
+	}
+
+	return &personalTouch{
+		image: image,
+		pages: selectPersonalTouchPages(lastPageNr, p.config.PersonalTouchDensity, p.config.PersonalTouchSeed),
+	}, nil
+}
+
+// addPersonalTouch places the image on the selected pages of a single file,
+// the page numbers have to be determined already
+func (p *Processor) addPersonalTouch(ctx *model.Context, file SingleFileToProcess, touch *personalTouch) error {
+	evenPages, oddPages := personalTouchPagesForFile(file.FirstPageNr-1, file.PageCount, touch.pages)
+
+	p.logger.Debug().Str("file", file.Filename).Ints("even", evenPages).Ints("odd", oddPages).Msg("Adding personal touch")
+
+	if err := addImageWatermark(ctx, evenPages, touch.image, evenMascotDescription); err != nil {
+		return err
+	}
+	return addImageWatermark(ctx, oddPages, touch.image, oddMascotDescription)
+}
+
+// personalTouchImage returns the user-provided image or the embedded mascot
+func (p *Processor) personalTouchImage() ([]byte, error) {
+	if p.config.PersonalTouchImage == "" {
+		return mascotImage, nil
+	}
+	image, err := os.ReadFile(p.config.PersonalTouchImage)
+	if err != nil {
+		return nil, fmt.Errorf("error reading personal touch image %s: %w", p.config.PersonalTouchImage, err)
+	}
+	return image, nil
+}
+
+// selectPersonalTouchPages returns the (continuous) page numbers to be decorated,
+// density is given in pages per hundred
+func selectPersonalTouchPages(lastPageNr, density int, seed int64) map[int]bool {
+	rng := rand.New(rand.NewSource(seed))
+
+	selected := make(map[int]bool)
+	for pageNr := 1; pageNr <= lastPageNr; pageNr++ {
+		if rng.Intn(100) < density {
+			selected[pageNr] = true
+		}
+	}
+	return selected
+}
+
+// personalTouchPagesForFile maps the selected page numbers to the pages within a single file,
+// separated into even and odd pages, as the image is mirrored to the outer margin
+func personalTouchPagesForFile(previousPageNr, pageCount int, selected map[int]bool) (evenPages, oddPages []int) {
+	for page := 1; page <= pageCount; page++ {
+		pageNr := previousPageNr + page
+		if !selected[pageNr] {
+			continue
+		}
+		if util.IsEven(pageNr) {
+			evenPages = append(evenPages, page)
+		} else {
+			oddPages = append(oddPages, page)
+		}
+	}
+	return evenPages, oddPages
+}
+
+func addImageWatermark(ctx *model.Context, pages []int, image []byte, description string) error {
+	if len(pages) == 0 {
+		return nil
+	}
+
+	// every watermark consumes its image reader, therefore a new one is created for every call
+	wm, err := api.ImageWatermarkForReader(bytes.NewReader(image), description, true, false, types.POINTS)
+	if err != nil {
+		return err
+	}
+
+	selectedPages := make(types.IntSet, len(pages))
+	for _, page := range pages {
+		selectedPages[page] = true
+	}
+	return pdfcpu.AddWatermarks(ctx, selectedPages, wm)
+}
@@ -0,0 +1,40 @@
+package pdf
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func TestPersonalTouchSelectionIsReproducible(t *testing.T) {
+	first := selectPersonalTouchPages(200, 10, 42)
+	second := selectPersonalTouchPages(200, 10, 42)
+
+	assert.Equal(t, first, second)
+	assert.NotEmpty(t, first)
+	assert.Less(t, len(first), 60, "density of 10 per hundred should select far less than 60 of 200 pages")
+}
+
+func TestPersonalTouchSelectionDensity(t *testing.T) {
+	assert.Len(t, selectPersonalTouchPages(50, 100, 7), 50)
+}
+
+func TestPersonalTouchPagesAreMirrored(t *testing.T) {
+	// file starts at page 5 of the handout
+	even, odd := personalTouchPagesForFile(4, 4, map[int]bool{5: true, 6: true, 8: true, 9: true})
+
+	assert.Equal(t, []int{2, 4}, even)
+	assert.Equal(t, []int{1}, odd)
+}
+
+func TestPersonalTouchWithEmbeddedMascot(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.PersonalTouch = true
+	cfg.PersonalTouchDensity = 100
+
+	assert.NotEmpty(t, mascotImage)
+	assert.NoError(t, ProcessPDFs(context.Background(), &cfg))
+}
@@ -0,0 +1,223 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"strings"
+	"text/tabwriter"
+)
+
+// Plan describes how the PDFs of the source directory would be processed, see Processor.Plan
+type Plan struct {
+	Chapters       []PlannedChapter
+	Skipped        []SkippedFile
+	Warnings       []string
+	TotalPageCount int
+}
+
+// PlannedChapter describes how a single file would be processed
+type PlannedChapter struct {
+	SourcePath             string
+	File                   string // relative to the source directory
+	ChapterNr              int
+	ChapterLabel           string // chapter number as shown, see ChapterNumberStyle, empty for front and back matter
+	Matter                 string // domain.MatterFront or domain.MatterBack, empty for chapters
+	ChapterTitle           string
+	OriginalPageCount      int
+	BlankPagesAdded        int
+	BlankPagesBefore       int
+	MergedBlankPagesBefore int // inserted before the chapter in the merged file only, see EvenifyScope
+	FirstPageNr            int
+	LastPageNr             int
+	Footer                 string // footer of the first page (after blank pages), empty if the chapter is not numbered
+}
+
+// PlanPDFs prints the plan for all PDFs as configured in cfg, nothing is written
+func PlanPDFs(ctx context.Context, cfg *domain.MinionConfig) error {
+	plan, err := NewProcessor(*cfg).Plan(ctx)
+	if plan != nil {
+		PrintPlan(os.Stdout, plan)
+	}
+	return err
+}
+
+// Plan collects, orders and validates all PDFs and determines chapter numbers,
+// blank pages and page ranges exactly like Run, but nothing is written.
+// Skipped files are part of the plan, also if planning fails in strict mode.
+func (p *Processor) Plan(ctx context.Context) (*Plan, error) {
+	releaseFontFile, err := p.useFontFile()
+	if err != nil {
+		return nil, err
+	}
+	defer releaseFontFile()
+
+	pdfFiles, nrOfValidPDFs, skipped, err := p.planAllChapters(ctx)
+	if err != nil {
+		if len(skipped) > 0 {
+			return &Plan{Skipped: skipped}, err
+		}
+		return nil, err
+	}
+
+	plan := &Plan{
+		Chapters:       make([]PlannedChapter, 0, nrOfValidPDFs),
+		Skipped:        skipped,
+		Warnings:       p.evenifyWarnings(nrOfValidPDFs, pdfFiles),
+		TotalPageCount: totalPageCount(nrOfValidPDFs, pdfFiles),
+	}
+	for i := 0; i < nrOfValidPDFs; i++ {
+		file := pdfFiles[i]
+		chapter := PlannedChapter{
+			SourcePath:             file.SourcePath,
+			File:                   p.relativeToSource(file.SourcePath),
+			ChapterNr:              file.ChapterNr,
+			ChapterLabel:           p.config.FormatChapterNr(file.ChapterNr),
+			ChapterTitle:           file.ChapterTitle,
+			OriginalPageCount:      file.PageCount - file.BlankPagesAdded - file.BlankPagesBefore,
+			BlankPagesAdded:        file.BlankPagesAdded,
+			BlankPagesBefore:       file.BlankPagesBefore,
+			MergedBlankPagesBefore: file.mergedBlankPagesBefore,
+			FirstPageNr:            file.FirstPageNr,
+			LastPageNr:             file.LastPageNr,
+			Matter:                 file.Matter,
+		}
+		if file.isMatter() {
+			chapter.ChapterLabel = ""
+		}
+		if file.isNumbered() {
+			chapter.Footer = p.footerText(file, 1+file.BlankPagesBefore, lastPageNr(nrOfValidPDFs, pdfFiles))
+		}
+		plan.Chapters = append(plan.Chapters, chapter)
+	}
+	return plan, nil
+}
+
+// planAllChapters collects (or takes from the handout), orders, validates and plans all files.
+// The skipped files are returned in any case, so they can be reported.
+func (p *Processor) planAllChapters(ctx context.Context) ([]SingleFileToProcess, int, []SkippedFile, error) {
+	cfg := &p.config
+	if cfg.ContinueFrom != "" {
+		if err := p.continueNumbering(cfg.ContinueFrom); err != nil {
+			return nil, 0, nil, err
+		}
+	}
+
+	handout, err := p.readHandout()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	var files []string
+	var unlisted []SkippedFile
+	if handout != nil {
+		// the handout lists the chapters in order
+		files = p.HandoutPDFs(handout)
+	} else {
+		if files, err = p.CollectCandidatePDFs(); err != nil {
+			return nil, 0, nil, fmt.Errorf("error collecting candidate PDFs: %w", err)
+		}
+		if files, unlisted, err = p.OrderCandidates(files); err != nil {
+			return nil, 0, nil, fmt.Errorf("error ordering candidate PDFs: %w", err)
+		}
+	}
+
+	pdfFiles, nrOfValidPDFs, invalid, err := p.ValidatePDFs(ctx, files)
+	if err != nil {
+		return nil, 0, unlisted, fmt.Errorf("error validating PDFs: %w", err)
+	}
+	skipped := append(unlisted, invalid...)
+
+	// files not listed in a manifest have been left out on purpose, only invalid files count in strict mode
+	if cfg.Strict && len(invalid) > 0 {
+		return nil, 0, skipped, fmt.Errorf("%w: %d of %d candidate files in %s are invalid (strict mode, nothing written)",
+			domain.ErrInvalidPDF, len(invalid), len(files), cfg.SourceDir)
+	}
+	if nrOfValidPDFs == 0 {
+		return nil, 0, skipped, fmt.Errorf("%w: none of the %d candidate files in %s is a valid PDF",
+			domain.ErrInvalidPDF, len(files), cfg.SourceDir)
+	}
+	if handout != nil {
+		p.applyHandout(handout, pdfFiles)
+	}
+	p.assignMatter(nrOfValidPDFs, pdfFiles)
+	if err := p.checkOutputNames(nrOfValidPDFs, pdfFiles); err != nil {
+		return nil, 0, skipped, err
+	}
+
+	if cfg.Verbose {
+		fmt.Fprintf(p.out, "Found %d PDF files\n", len(files))
+	}
+	p.logger.Debug().Int("fileCount", len(files)).Msg("Found files")
+
+	p.PlanChapters(nrOfValidPDFs, pdfFiles)
+	return pdfFiles, nrOfValidPDFs, skipped, nil
+}
+
+// checkOutputNames ensures that no chapter is overwritten by the merged file, the table of contents or the report,
+// which are written into the target directory, too
+func (p *Processor) checkOutputNames(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) error {
+	generated := make(map[string]string)
+	if p.config.Merge {
+		generated[p.config.MergeFileName] = "merged file"
+	}
+	if p.config.TOC {
+		generated[domain.DefaultTOCFileName] = "table of contents"
+	}
+	if p.config.Report != "" {
+		generated[filepath.Clean(p.config.Report)] = "report"
+	}
+
+	for i := 0; i < nrOfValidPDFs; i++ {
+		relPath := p.relativeToSource(pdfFiles[i].SourcePath)
+		for name, kind := range generated {
+			// target directories may be case-insensitive
+			if strings.EqualFold(relPath, name) {
+				return fmt.Errorf("%w: chapter %s would be overwritten by the %s, rename the file or choose another name",
+					domain.ErrInvalidConfig, relPath, kind)
+			}
+		}
+	}
+	return nil
+}
+
+// PrintPlan prints the plan to w as a table, followed by warnings and the skipped files
+func PrintPlan(w io.Writer, plan *Plan) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Chapter\tFile\tPages\tBlank\tStart\tEnd\tFooter")
+	for _, chapter := range plan.Chapters {
+		blank := ""
+		if chapter.BlankPagesBefore > 0 {
+			blank = fmt.Sprintf("+%d before", chapter.BlankPagesBefore)
+		}
+		if chapter.MergedBlankPagesBefore > 0 {
+			blank = fmt.Sprintf("+%d before (merged)", chapter.MergedBlankPagesBefore)
+		}
+		if chapter.BlankPagesAdded > 0 {
+			blank = strings.TrimSpace(blank + fmt.Sprintf(" +%d", chapter.BlankPagesAdded))
+		}
+		label := chapter.ChapterLabel
+		if chapter.Matter != "" {
+			label = chapter.Matter
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\t%d\t%s\n", label, chapter.File,
+			chapter.OriginalPageCount, blank, chapter.FirstPageNr, chapter.LastPageNr, chapter.Footer)
+	}
+	tw.Flush()
+
+	if len(plan.Chapters) > 0 {
+		// front and back matter are no chapters
+		chapters := 0
+		for _, chapter := range plan.Chapters {
+			if chapter.Matter == "" {
+				chapters++
+			}
+		}
+		fmt.Fprintf(w, "%d chapters in %d files, %d pages, nothing has been written\n", chapters, len(plan.Chapters), plan.TotalPageCount)
+	}
+	printWarnings(w, plan.Warnings)
+	printSkippedFiles(w, plan.Skipped)
+}
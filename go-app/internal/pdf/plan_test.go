@@ -0,0 +1,61 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func TestPlanWritesNothing(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "FourFilesTwoPdfs"
+	cfg.TargetDir = filepath.Join(t.TempDir(), "target")
+
+	plan, err := NewProcessor(cfg).Plan(context.Background())
+	assert.NoError(t, err)
+	assert.NoDirExists(t, cfg.TargetDir)
+
+	assert.Equal(t, 6, plan.TotalPageCount)
+	assert.Len(t, plan.Skipped, 1)
+	assert.Equal(t, []PlannedChapter{
+		{
+			SourcePath: filepath.Join(cfg.SourceDir, "sample-A4-portrait-1pg.pdf"), File: "sample-A4-portrait-1pg.pdf",
+			ChapterNr: 1, ChapterLabel: "1", ChapterTitle: "sample A4 portrait 1pg", OriginalPageCount: 1, BlankPagesAdded: 1,
+			FirstPageNr: 1, LastPageNr: 2, Footer: "Chapter 1 - Page 1 of 6",
+		},
+		{
+			SourcePath: filepath.Join(cfg.SourceDir, "sample-A4-portrait-3pgs.pdf"), File: "sample-A4-portrait-3pgs.pdf",
+			ChapterNr: 2, ChapterLabel: "2", ChapterTitle: "sample A4 portrait 3pgs", OriginalPageCount: 3, BlankPagesAdded: 1,
+			FirstPageNr: 3, LastPageNr: 6, Footer: "Chapter 2 - Page 3 of 6",
+		},
+	}, plan.Chapters)
+}
+
+func TestPlanInStrictModeReportsSkippedFiles(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "FourFilesTwoPdfs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Strict = true
+
+	plan, err := NewProcessor(cfg).Plan(context.Background())
+	assert.True(t, errors.Is(err, domain.ErrInvalidPDF))
+	assert.Empty(t, plan.Chapters)
+	assert.Len(t, plan.Skipped, 1)
+}
+
+func TestPlanStartsWithFirstChapterAndPage(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "FourFilesTwoPdfs"
+	cfg.FirstChapter = 8
+	cfg.FirstPage = 101
+
+	plan, err := NewProcessor(cfg).Plan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 6, plan.TotalPageCount)
+	assert.Equal(t, []int{8, 9}, []int{plan.Chapters[0].ChapterNr, plan.Chapters[1].ChapterNr})
+	assert.Equal(t, []int{101, 103}, []int{plan.Chapters[0].FirstPageNr, plan.Chapters[1].FirstPageNr})
+	assert.Equal(t, "Chapter 9 - Page 103 of 106", plan.Chapters[1].Footer)
+}
@@ -1,51 +1,250 @@
 package pdf
 
 import (
+	"context"
 	"fmt"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"io"
+	"os"
 	"pdfminion/internal/domain"
-	"sort"
+	"time"
 )
 
-var appConfig domain.MinionConfig
+// Processor runs the processing pipeline (evenify, numbering, header, labels, toc, merge)
+// for a single configuration. Processors do not share state, so several of them
+// can be used independently within the same program.
+type Processor struct {
+	config domain.MinionConfig
 
-func ProcessPDFs(cfg *domain.MinionConfig) error {
-	log.Debug().Msg("Starting PDF processing") // Only shown in debug mode
+	// all files are written to outputDir, a staging directory next to the target directory
+	outputDir string
 
-	// store configuration so it becomes usable in other functions
-	appConfig = *cfg
+	// the relaxedConf is VERY specific to the pdfcpu library
+	relaxedConf *model.Configuration
 
+	// name of the installed FontFile, empty if the built-in fonts are used
+	fontName string
+
+	// value of the {date} placeholder, the same for all pages of a run
+	date string
+
+	// progress and results are printed to out, see SetOutput
+	out io.Writer
+
+	// debug messages and errors are logged to logger, see SetLogger
+	logger zerolog.Logger
+}
+
+// Result describes the outcome of a processing run
+type Result struct {
+	Files                 []FileResult
+	Skipped               []SkippedFile
+	Warnings              []string // e.g. odd page counts, see EvenifyPolicyWarn
+	TotalPageCount        int
+	TOCFile               string // empty unless a table of contents was created
+	MergedFile            string // empty unless files were merged
+	MergedBlankPagesAdded int    // blank pages appended to the merged file, see EvenifyScope
+	ReportFile            string // empty unless a report was written
+}
+
+// FileResult describes a single processed file
+type FileResult struct {
+	SourcePath       string
+	OutputPath       string
+	InputByteCount   int64
+	ChapterNr        int // 0 for front and back matter
+	ChapterTitle     string
+	Matter           string // domain.MatterFront or domain.MatterBack, empty for chapters
+	FirstPageNr      int
+	LastPageNr       int
+	BlankPagesAdded  int
+	BlankPagesBefore int
+}
+
+// NewProcessor creates a Processor for a copy of cfg.
+// The configuration is expected to be validated already.
+func NewProcessor(cfg domain.MinionConfig) *Processor {
+	p := &Processor{config: cfg, date: time.Now().Format(domain.TemplateDateFormat), out: os.Stdout, logger: log.Logger}
+	p.InitializePDFInternals()
+	return p
+}
+
+// SetOutput sets the writer for progress messages, results, warnings and skipped files, os.Stdout by default.
+// Errors are returned, not printed.
+func (p *Processor) SetOutput(out io.Writer) {
+	p.out = out
+}
+
+// SetLogger sets the logger for debug messages and errors, the global zerolog logger by default
+func (p *Processor) SetLogger(logger zerolog.Logger) {
+	p.logger = logger
+}
+
+// InitializePDFInternals prepares the pdfcpu configuration.
+// The configured font file is installed by Run and Plan, and removed when they have finished.
+func (p *Processor) InitializePDFInternals() {
+	p.relaxedConf = model.NewDefaultConfiguration()
+	p.relaxedConf.ValidationMode = model.ValidationRelaxed
+}
+
+// ProcessPDFs processes all PDFs as configured in cfg, until ctx is cancelled
+func ProcessPDFs(ctx context.Context, cfg *domain.MinionConfig) error {
+	_, err := NewProcessor(*cfg).Run(ctx)
+	return err
+}
+
+// Run processes all PDFs from the source directory into the target directory.
+// Every file is read once, processed in memory and written once.
+// Cancellation of ctx is checked before every file, the table of contents and the merge.
+// All files are written to a staging directory first and moved into the target directory
+// only after every file has been processed successfully.
+// If processing is cancelled, the target directory is left unchanged and the returned Result
+// lists the chapters completed so far (without output path), together with an error wrapping ctx.Err().
+func (p *Processor) Run(ctx context.Context) (*Result, error) {
+	p.logger.Debug().Msg("Starting PDF processing") // Only shown in debug mode
+	started := time.Now()
+
+	releaseFontFile, err := p.useFontFile()
+	if err != nil {
+		return nil, err
+	}
+	defer releaseFontFile()
+
+	cfg := &p.config
 	if cfg.Verbose {
-		fmt.Println("Starting PDF processing")
+		fmt.Fprintln(p.out, "Starting PDF processing")
 	}
 
-	InitializePDFInternals()
+	pdfFiles, nrOfValidPDFs, skipped, err := p.planAllChapters(ctx)
+	// the report is printed at the end of the run, so it does not get lost in the output of later stages
+	defer printSkippedFiles(p.out, skipped)
+	if err != nil {
+		return nil, err
+	}
+	warnings := p.evenifyWarnings(nrOfValidPDFs, pdfFiles)
+	defer printWarnings(p.out, warnings)
 
-	// TODO: remove cfg from function signature
-	files, err := CollectCandidatePDFs()
+	if err := CheckTargetDir(cfg.TargetDir, cfg.Force); err != nil {
+		return nil, fmt.Errorf("error preparing target directory: %w", err)
+	}
 
+	stage, err := newStaging(cfg.TargetDir, p.logger)
 	if err != nil {
-		return fmt.Errorf("error collecting candidate PDFs: %w", err)
+		return nil, err
 	}
+	defer stage.cleanup()
 
-	sort.Slice(files, func(i, j int) bool {
-		return files[i] < files[j]
-	})
+	p.outputDir = stage.dir
+	AssignOutputFiles(pdfFiles, cfg.SourceDir, p.outputDir)
 
-	pdfFiles, nrOfValidPDFs := ValidatePDFs(files)
+	err = p.ProcessAllFiles(ctx, nrOfValidPDFs, pdfFiles)
 
-	err = CopyValidatedPDFs(pdfFiles, cfg.SourceDir, cfg.TargetDir, cfg.Force)
+	result := newResult(nrOfValidPDFs, pdfFiles)
+	result.Skipped = skipped
+	result.Warnings = warnings
+
+	if ctx.Err() != nil {
+		// the target directory is left unchanged
+		for i := range result.Files {
+			result.Files[i].OutputPath = ""
+		}
+		printCompletedChapters(p.out, result, nrOfValidPDFs)
+		return result, fmt.Errorf("processing interrupted: %w", ctx.Err())
+	}
 	if err != nil {
-		return fmt.Errorf("error during copy: %w", err)
+		return nil, fmt.Errorf("error processing files: %w", err)
 	}
 
-	if cfg.Verbose {
-		fmt.Printf("Found %d PDF files\n", len(files))
+	if cfg.TOC {
+		if result.TOCFile, err = p.CreateTableOfContents(ctx, nrOfValidPDFs, pdfFiles); err != nil {
+			return nil, fmt.Errorf("error during table of contents generation: %w", err)
+		}
 	}
-	log.Debug().Int("fileCount", len(files)).Msg("Found files")
 
-	Evenify(nrOfValidPDFs, pdfFiles)
-	AddPageNumbersToAllFiles(nrOfValidPDFs, pdfFiles)
+	if cfg.Merge {
+		if result.MergedFile, result.MergedBlankPagesAdded, err = p.MergeAllFiles(ctx, result.TOCFile, nrOfValidPDFs, pdfFiles); err != nil {
+			return nil, fmt.Errorf("error during merge: %w", err)
+		}
+	}
 
+	if cfg.Report != "" {
+		if result.ReportFile, err = p.writeReport(stage, result, pdfFiles[:nrOfValidPDFs], started); err != nil {
+			return nil, fmt.Errorf("error writing report: %w", err)
+		}
+	}
+
+	if err := p.commitResult(stage, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// commitResult moves all files of result into the target directory and updates their paths
+func (p *Processor) commitResult(stage *staging, result *Result) error {
+	stagedFiles := make([]string, 0, len(result.Files)+3)
+	for _, file := range result.Files {
+		stagedFiles = append(stagedFiles, file.OutputPath)
+	}
+	for _, file := range []string{result.TOCFile, result.MergedFile, result.ReportFile} {
+		if file != "" {
+			stagedFiles = append(stagedFiles, file)
+		}
+	}
+
+	if err := stage.commit(stagedFiles); err != nil {
+		return fmt.Errorf("error moving results into target directory: %w", err)
+	}
+
+	for i := range result.Files {
+		result.Files[i].OutputPath = stage.targetPath(result.Files[i].OutputPath)
+		fmt.Fprintf(p.out, "Written: %s\n", result.Files[i].OutputPath)
+	}
+	if result.TOCFile != "" {
+		result.TOCFile = stage.targetPath(result.TOCFile)
+	}
+	if result.MergedFile != "" {
+		result.MergedFile = stage.targetPath(result.MergedFile)
+	}
+	if result.ReportFile != "" {
+		result.ReportFile = stage.targetPath(result.ReportFile)
+		fmt.Fprintf(p.out, "Report: %s\n", result.ReportFile)
+	}
 	return nil
 }
+
+// newResult collects the results of all files which have been written
+func newResult(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) *Result {
+	result := &Result{
+		Files:          make([]FileResult, 0, nrOfValidPDFs),
+		TotalPageCount: totalPageCount(nrOfValidPDFs, pdfFiles),
+	}
+	for i := 0; i < nrOfValidPDFs; i++ {
+		if !pdfFiles[i].written {
+			continue
+		}
+		result.Files = append(result.Files, FileResult{
+			SourcePath:       pdfFiles[i].SourcePath,
+			OutputPath:       pdfFiles[i].Filename,
+			InputByteCount:   pdfFiles[i].OrigByteCount,
+			ChapterNr:        pdfFiles[i].ChapterNr,
+			ChapterTitle:     pdfFiles[i].ChapterTitle,
+			Matter:           pdfFiles[i].Matter,
+			FirstPageNr:      pdfFiles[i].FirstPageNr,
+			LastPageNr:       pdfFiles[i].LastPageNr,
+			BlankPagesAdded:  pdfFiles[i].BlankPagesAdded,
+			BlankPagesBefore: pdfFiles[i].BlankPagesBefore,
+		})
+	}
+	return result
+}
+
+// printCompletedChapters reports which chapters have been completed before processing was interrupted
+func printCompletedChapters(w io.Writer, result *Result, nrOfValidPDFs int) {
+	fmt.Fprintf(w, "Interrupted, %d of %d chapters completed, the target directory has not been changed:\n",
+		len(result.Files), nrOfValidPDFs)
+	for _, file := range result.Files {
+		fmt.Fprintf(w, "  %d: %s\n", file.ChapterNr, file.ChapterTitle)
+	}
+}
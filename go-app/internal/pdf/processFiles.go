@@ -1,219 +1,356 @@
 package pdf
 
 import (
+	"context"
 	"fmt"
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
-	"github.com/rs/zerolog/log"
 	"io"
 	"os"
 	"path/filepath"
+	"pdfminion/internal/domain"
 	"pdfminion/internal/util"
-	"strconv"
+	"strings"
+	"time"
 )
 
 type SingleFileToProcess struct {
-	Filename      string
+	Filename      string // path of the processed file, once AssignOutputFiles has been called
+	SourcePath    string
 	PageCount     int
 	OrigByteCount int64
-}
 
-var (
-	// the relaxedConf is VERY specific to the pdfcpu library
-	relaxedConf *model.Configuration
-)
+	// set by PlanChapters, used for numbering and table of contents
+	BlankPagesAdded  int // appended to the file, part of PageCount
+	BlankPagesBefore int // inserted before the first page, part of PageCount
+	ChapterNr        int
+	ChapterTitle     string
+	FirstPageNr      int
+	LastPageNr       int
+
+	// chapter options from the handout manifest, nil without handout
+	chapter *domain.HandoutChapter
+
+	// domain.MatterFront or domain.MatterBack, empty for numbered chapters, see assignMatter
+	Matter string
+
+	// blank pages inserted before the file when merging, not part of PageCount, see EvenifyScope
+	mergedBlankPagesBefore int
 
-func InitializePDFInternals() {
-	relaxedConf = model.NewDefaultConfiguration()
-	relaxedConf.ValidationMode = model.ValidationRelaxed
+	// every file is read only once, all stages are applied to this context in memory.
+	// It is released as soon as the processed file has been written.
+	ctx      *model.Context
+	written  bool
+	duration time.Duration // processing time, for the report
 }
 
-// CollectCandidatePDFs collects all PDF files in the source directory
-// and aborts if no PDF files are present
-func CollectCandidatePDFs() ([]string, error) {
-	var nrOfCandidatePDFs int
+// SkippedFile is a candidate file which has not been processed
+type SkippedFile struct {
+	Filename string
+	Reason   string
+}
+
+// CollectCandidatePDFs collects all PDF files in the source directory,
+// including sub-folders if Recursive is set, restricted by the Include and Exclude patterns.
+// It returns domain.ErrNoPDFsFound if no PDF files are present
+func (p *Processor) CollectCandidatePDFs() ([]string, error) {
+	filter := candidateFilter{
+		recursive: p.config.Recursive,
+		include:   p.config.Include,
+		exclude:   p.config.Exclude,
+		skipDir:   p.config.TargetDir,
+	}
 
-	files, err, nrOfCandidatePDFs := getNumberOfCandidatePDFs(appConfig.SourceDir)
-	if appConfig.Verbose {
-		fmt.Printf("Found %d PDF files in %s\n", nrOfCandidatePDFs, appConfig.SourceDir)
+	files, err := filter.collectCandidates(p.config.SourceDir)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("Error")
+		return nil, err
+	}
+	nrOfCandidatePDFs := len(files)
+	if p.config.Verbose {
+		fmt.Fprintf(p.out, "Found %d PDF files in %s\n", nrOfCandidatePDFs, p.config.SourceDir)
 	}
 
-	// exit if no PDF files can be found
 	if nrOfCandidatePDFs == 0 {
-		fmt.Printf("No PDF files found in %s\n", appConfig.SourceDir)
-		os.Exit(1)
+		return nil, fmt.Errorf("%w in %s", domain.ErrNoPDFsFound, p.config.SourceDir)
 	}
 
-	return files, err
+	return files, nil
 }
 
-func getNumberOfCandidatePDFs(sourceDir string) ([]string, error, int) {
-	// collect all candidate PDFs with Glob
-	// "candidate" means, PDF has not been validated
-	pattern := filepath.Join(sourceDir, "*.pdf")
-	files, err := filepath.Glob(pattern)
-	if err != nil {
-		log.Error().Err(err).Msg("Error")
-	}
+// ValidatePDFs reads and validates all candidate files concurrently and counts their pages.
+// The files are kept in memory for processing, so they need not be read again.
+// Invalid files are not processed, they are returned as skipped files together with the reason.
+// Both valid and skipped files keep the order of files.
+// An error is only returned if ctx is cancelled.
+func (p *Processor) ValidatePDFs(ctx context.Context, files []string) ([]SingleFileToProcess, int, []SkippedFile, error) {
 
-	return files, err, len(files)
-}
+	// every candidate is either valid or skipped
+	candidates := make([]SingleFileToProcess, len(files))
+	reasons := make([]string, len(files))
+
+	err := p.forEachFile(ctx, len(files), func(i int) error {
+		file := files[i]
+
+		pdfCtx, err := p.readContextFile(file)
+		if err != nil {
+			p.logger.Printf("%v is not a valid PDF, %v\n", file, err)
+			reasons[i] = "not a valid PDF: " + err.Error()
+			return nil
+		}
 
-func ValidatePDFs(files []string) ([]SingleFileToProcess, int) {
+		if err := pdfCtx.EnsurePageCount(); err != nil {
+			p.logger.Error().Err(err).Str("file: %v", file).Msg("Error counting pages")
+			reasons[i] = "pages cannot be counted: " + err.Error()
+			return nil
+		}
+
+		candidates[i] = SingleFileToProcess{
+			Filename:      filepath.Base(file),
+			SourcePath:    file,
+			PageCount:     pdfCtx.PageCount,
+			OrigByteCount: pdfCtx.Read.FileSize,
+			ChapterTitle:  chapterTitleFromFilename(file),
+			ctx:           pdfCtx,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, nil, err
+	}
 
 	validPDFs := make([]SingleFileToProcess, 0)
 	nrOfValidPDFs := 0
+	skipped := make([]SkippedFile, 0)
 
-	for _, file := range files {
-		err := api.ValidateFile(file, relaxedConf)
-		if err != nil {
-			log.Printf("%v is not a valid PDF, %v\n", file, err)
+	for i, file := range files {
+		if reasons[i] != "" {
+			skipped = append(skipped, SkippedFile{Filename: file, Reason: reasons[i]})
 			continue
 		}
+		validPDFs = append(validPDFs, candidates[i])
+		nrOfValidPDFs++
+	}
 
-		pageCount, err := api.PageCountFile(file)
-		if err != nil {
-			log.Error().Err(err).Str("file: %v", file).Msg("Error counting pages")
-			continue
-		}
+	return validPDFs, nrOfValidPDFs, skipped, nil
+}
 
-		validPDFs = append(validPDFs, SingleFileToProcess{
-			Filename:  filepath.Base(file),
-			PageCount: pageCount,
-		})
-		nrOfValidPDFs++
+// printSkippedFiles lists all files which have not been processed,
+// as later chapters are renumbered without them
+func printSkippedFiles(w io.Writer, skipped []SkippedFile) {
+	if len(skipped) == 0 {
+		return
 	}
+	fmt.Fprintf(w, "Skipped %d file(s):\n", len(skipped))
+	for _, file := range skipped {
+		fmt.Fprintf(w, "  %s: %s\n", file.Filename, file.Reason)
+	}
+}
 
-	return validPDFs, nrOfValidPDFs
+// CheckTargetDir ensures that the target directory is empty, unless force is set
+func CheckTargetDir(targetDir string, force bool) error {
+	if force {
+		return nil
+	}
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		return fmt.Errorf("error reading target directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%w: %q", domain.ErrTargetNotEmpty, targetDir)
+	}
+	return nil
 }
 
-func CopyValidatedPDFs(validPDFs []SingleFileToProcess, sourceDir, targetDir string, force bool) error {
-	// Check if target directory is empty, unless force flag is set
-	if !force {
-		entries, err := os.ReadDir(targetDir)
+// AssignOutputFiles determines the path of every processed file within outputDir.
+// The path relative to sourceDir is kept, so sub-folders are mirrored.
+func AssignOutputFiles(validPDFs []SingleFileToProcess, sourceDir, outputDir string) {
+	for i := range validPDFs {
+		relPath, err := filepath.Rel(sourceDir, validPDFs[i].SourcePath)
 		if err != nil {
-			return fmt.Errorf("error reading target directory: %w", err)
-		}
-		if len(entries) > 0 {
-			return fmt.Errorf("target directory is not empty")
+			relPath = filepath.Base(validPDFs[i].SourcePath)
 		}
+		validPDFs[i].Filename = filepath.Join(outputDir, relPath)
 	}
+}
 
-	for i := range validPDFs {
-		sourcePath := filepath.Join(sourceDir, validPDFs[i].Filename)
-		targetPath := filepath.Join(targetDir, validPDFs[i].Filename)
-
-		// Check if file exists and skip if not forcing overwrite
-		if !force {
-			if _, err := os.Stat(targetPath); err == nil {
-				fmt.Printf("Skipping existing file: %s\n", targetPath)
-				continue
-			}
+// PlanChapters determines chapter number, blank pages and page range of every file.
+// All files are planned before any of them is processed, as the footer shows the total page count.
+// Front and back matter get chapter number 0 and page numbers of their own, starting at 1.
+func (p *Processor) PlanChapters(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) {
+	// previousPageNr is the last page number of the previous chapter
+	var previousPageNr = p.config.FirstPage - 1
+	var previousChapterNr = p.config.FirstChapter - 1
+	var previousMatterPageNr = 0
+	// pagesBefore counts the pages preceding a file in the merged output
+	var pagesBefore = 0
+
+	frontMatter := frontMatterCount(nrOfValidPDFs, pdfFiles)
+	for i := 0; i < nrOfValidPDFs; i++ {
+		if i == frontMatter && p.config.TOC {
+			// the table of contents follows the front matter and is labeled with its page numbers,
+			// so back matter continues after the pages of the table of contents
+			tocPageCount := p.tocPageCount(nrOfValidPDFs - frontMatter - backMatterCount(nrOfValidPDFs, pdfFiles))
+			pagesBefore += tocPageCount
+			previousMatterPageNr += tocPageCount
 		}
 
-		// Open the source file
-		originalFile, err := os.Open(sourcePath)
-		if err != nil {
-			return fmt.Errorf("error opening source file %s: %w", sourcePath, err)
+		previous := &previousPageNr
+		if pdfFiles[i].isMatter() {
+			previous = &previousMatterPageNr
+			pdfFiles[i].ChapterNr = 0
+		} else {
+			pdfFiles[i].ChapterNr = pdfFiles[i].nextChapterNr(previousChapterNr)
+			previousChapterNr = pdfFiles[i].ChapterNr
 		}
 
-		// Ensure the source file is closed after use
-		defer func(file *os.File) {
-			if closeErr := file.Close(); closeErr != nil {
-				log.Printf("error closing source file %s: %v", sourcePath, closeErr)
+		p.planBlankPages(&pdfFiles[i], pagesBefore)
+		pdfFiles[i].FirstPageNr = *previous + pdfFiles[i].mergedBlankPagesBefore + 1
+		pdfFiles[i].LastPageNr = pdfFiles[i].FirstPageNr + pdfFiles[i].PageCount - 1
+		*previous = pdfFiles[i].LastPageNr
+		pagesBefore += pdfFiles[i].mergedBlankPagesBefore + pdfFiles[i].PageCount
+
+		p.logger.Debug().Str("file", pdfFiles[i].Filename).Int("start", pdfFiles[i].FirstPageNr).Int("end", pdfFiles[i].LastPageNr).Msg("Planned chapter")
+		if p.config.Verbose {
+			fmt.Fprintf(p.out, "File %s starts %d, ends %d\n", pdfFiles[i].Filename, pdfFiles[i].FirstPageNr, pdfFiles[i].LastPageNr)
+			if pdfFiles[i].BlankPagesAdded > 0 || pdfFiles[i].BlankPagesBefore > 0 {
+				fmt.Fprintf(p.out, "File %s will be evenified\n", pdfFiles[i].Filename)
 			}
-		}(originalFile)
-
-		// Create the target file
-		newFile, err := os.Create(targetPath)
-		if err != nil {
-			originalFile.Close() // Ensure source file is closed before returning
-			return fmt.Errorf("error creating target file %s: %w", targetPath, err)
 		}
+	}
+}
 
-		// Ensure the target file is closed after use
-		defer func(file *os.File) {
-			if closeErr := file.Close(); closeErr != nil {
-				log.Printf("error closing target file %s: %v", targetPath, closeErr)
-			}
-		}(newFile)
-
-		bytesWritten, err := io.Copy(newFile, originalFile)
-		if err != nil {
-			return fmt.Errorf("error copying file %s: %w", sourcePath, err)
+// ProcessAllFiles applies all stages to every file in memory and writes each file once.
+// Files are processed concurrently by up to Jobs workers, the output does not depend on their number.
+// It has to be called after PlanChapters. Cancellation of ctx is checked before every file.
+func (p *Processor) ProcessAllFiles(ctx context.Context, nrOfValidPDFs int, pdfFiles []SingleFileToProcess) error {
+	var lastPageNr = lastPageNr(nrOfValidPDFs, pdfFiles)
+
+	var personalTouch *personalTouch
+	if p.config.PersonalTouch {
+		var err error
+		if personalTouch, err = p.newPersonalTouch(lastPageNr); err != nil {
+			return err
 		}
+	}
 
-		validPDFs[i].OrigByteCount = bytesWritten
-		fmt.Printf("Copied: %s\n", targetPath)
-
-		// update the filename in the slice with the full path
-		validPDFs[i].Filename = targetPath
+	err := p.forEachFile(ctx, nrOfValidPDFs, func(i int) error {
+		return p.processFile(&pdfFiles[i], lastPageNr, personalTouch)
+	})
+	if err != nil {
+		return err
 	}
 
+	if p.config.Verbose {
+		withHeader := 0
+		for i := 0; i < nrOfValidPDFs; i++ {
+			if p.hasRunningHeader(pdfFiles[i]) {
+				withHeader++
+			}
+		}
+		if withHeader > 0 {
+			fmt.Fprintf(p.out, "Running header added to %d files\n", withHeader)
+		}
+	}
+	if p.config.Verbose && personalTouch != nil {
+		fmt.Fprintf(p.out, "Personal touch added to %d pages\n", len(personalTouch.pages))
+	}
 	return nil
 }
 
-func AddPageNumbersToAllFiles(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) {
-	// currentOffset is the _previous_ pagenumber
-	var currentOffset = 0
-
+// totalPageCount sums up the pages of all files, including blank pages
+func totalPageCount(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) int {
+	total := 0
 	for i := 0; i < nrOfValidPDFs; i++ {
-		var currentFilePageCount = pdfFiles[i].PageCount
-		var currentFileName = pdfFiles[i].Filename
-
-		log.Debug().Str("file", currentFileName).Int("start", currentOffset+1).Int("end", currentOffset+currentFilePageCount).Msg("Adding page numbers")
-
-		if appConfig.Verbose {
-			fmt.Printf("File %s starts %d, ends %d\n", currentFileName, currentOffset+1,
-				currentOffset+currentFilePageCount)
-		}
+		total += pdfFiles[i].PageCount
+	}
+	return total
+}
 
-		err := api.AddWatermarksMapFile(currentFileName,
-			"",
-			watermarkConfigurationForFile(i+1,
-				currentOffset,
-				currentFilePageCount),
-			relaxedConf)
-		if err != nil {
-			log.Error().Err(err).Str("file", currentFileName).Msg("Error adding watermarks")
+// lastPageNr returns the last page number of the chapters, shown as total page count in the footer.
+// It equals the total page count unless numbering starts after page 1 (see FirstPage and ContinueFrom)
+// or there is front or back matter.
+func lastPageNr(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) int {
+	last := 0
+	for i := 0; i < nrOfValidPDFs; i++ {
+		if !pdfFiles[i].isMatter() {
+			last = pdfFiles[i].LastPageNr
 		}
-		currentOffset += currentFilePageCount
 	}
+	return last
 }
 
 // create a map[int] of TextWatermark configurations
-func watermarkConfigurationForFile(chapterNr, previousPageNr, pageCount int) map[int]*model.Watermark {
+func (p *Processor) watermarkConfigurationForFile(file SingleFileToProcess, totalPageCount int) map[int]*model.Watermark {
 
 	wmcs := make(map[int]*model.Watermark)
 
-	for page := 1; page <= (pageCount); page++ {
-		var currentPageNr = previousPageNr + page
-		var chapterStr = appConfig.ChapterPrefix + strconv.Itoa(chapterNr)
-		var pageStr = appConfig.PageNrPrefix + strconv.Itoa(currentPageNr)
-
-		wmcs[page], _ = api.TextWatermark(chapterStr+appConfig.Separator+pageStr,
-			waterMarkDescription(currentPageNr), true, false, types.POINTS)
+	for page := 1; page <= file.PageCount; page++ {
+		footer := p.footerText(file, page, totalPageCount)
+		if footer == "" {
+			continue
+		}
+		wmcs[page], _ = api.TextWatermark(footer, p.footerDescription(file.FirstPageNr-1+page), true, false, types.POINTS)
 	}
 	return wmcs
 }
 
-const fontColorSize = "font:Helvetica, points:16, scale: 0.9 abs, rot: 0, color: 0.5 0.5 0.5"
-
-// creates a pdfcpu TextWatermark description
-func waterMarkDescription(pageNumber int) string {
+// footerText renders the footer template of a page, by default e.g. "Chapter 3 - Page 17 of 142"
+func (p *Processor) footerText(file SingleFileToProcess, pageInChapter, totalPageCount int) string {
+	pageNr := file.FirstPageNr - 1 + pageInChapter
+	if file.isMatter() {
+		if p.config.MatterNumbering == domain.MatterNumberingRoman {
+			return domain.FormatNumber(pageNr, domain.NumberStyleLowerRoman)
+		}
+		return ""
+	}
+	return domain.RenderTemplate(p.config.FooterTemplateFor(pageNr), p.templateValues(file, pageInChapter, totalPageCount))
+}
 
-	const evenPos string = "position: bl"
-	const evenOffset string = "offset: 20 6"
-	const oddPos string = "position: br"
-	const oddOffset string = "offset: -20 6"
+// templateValues returns the values of all template placeholders for a page of file
+func (p *Processor) templateValues(file SingleFileToProcess, pageInChapter, totalPageCount int) domain.TemplateValues {
+	values := domain.TemplateValues{
+		Chapter:      p.config.FormatChapterNr(file.ChapterNr),
+		Page:         p.config.FormatPageNr(file.ChapterNr, pageInChapter, file.FirstPageNr-1+pageInChapter),
+		Total:        totalPageCount,
+		ChapterPage:  domain.FormatNumber(pageInChapter, p.config.PageNumberStyle),
+		ChapterPages: file.PageCount,
+		ChapterTitle: file.ChapterTitle,
+		File:         filepath.Base(file.SourcePath),
+		Date:         p.date,
+		Course:       p.config.Course,
+		Version:      domain.AppVersion(),
+	}
+	// front and back matter have no chapter number, their pages are numbered separately
+	if file.isMatter() {
+		values.Chapter = ""
+		values.Page = domain.FormatNumber(file.FirstPageNr-1+pageInChapter, domain.NumberStyleLowerRoman)
+		values.ChapterPage = domain.FormatNumber(pageInChapter, domain.NumberStyleLowerRoman)
+	}
+	return values
+}
 
-	positionAndOffset := ""
+// footerDescription creates a pdfcpu TextWatermark description for the footer,
+// as configured by FooterPosition, FooterOffsetX/Y, FooterFont, FooterFontSize, FooterColor and FooterOpacity
+func (p *Processor) footerDescription(pageNumber int) string {
+	vertical, horizontal, _ := strings.Cut(p.config.FooterPosition, "-")
+
+	// the outer margin is on the left side of even pages, the inner margin on the right side
+	anchor, offsetX := "c", 0
+	switch {
+	case horizontal == "outer" && util.IsEven(pageNumber), horizontal == "inner" && !util.IsEven(pageNumber):
+		anchor, offsetX = "l", p.config.FooterOffsetX
+	case horizontal == "outer", horizontal == "inner":
+		anchor, offsetX = "r", -p.config.FooterOffsetX
+	}
 
-	if util.IsEven(pageNumber) {
-		positionAndOffset = evenPos + "," + evenOffset
-	} else {
-		positionAndOffset = oddPos + "," + oddOffset
+	position, offsetY := "b"+anchor, p.config.FooterOffsetY
+	if vertical == "top" {
+		position, offsetY = "t"+anchor, -p.config.FooterOffsetY
 	}
-	return fontColorSize + "," + positionAndOffset
+
+	return fmt.Sprintf("font:%s, points:%d, scale: 0.9 abs, rot: 0, color: %s, opacity: %g, position: %s, offset: %d %d",
+		p.stampFont(p.config.FooterFont), p.config.FooterFontSize, p.config.FooterColor, p.config.FooterOpacity,
+		position, offsetX, offsetY)
 }
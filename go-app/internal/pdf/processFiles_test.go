@@ -0,0 +1,135 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func TestFooterTextWithTotal(t *testing.T) {
+	p := NewProcessor(domain.NewDefaultEnglishConfig())
+	file := SingleFileToProcess{ChapterNr: 3, PageCount: 9, FirstPageNr: 16}
+
+	assert.Equal(t, "Chapter 3 - Page 17 of 142", p.footerText(file, 2, 142))
+
+	p.config.PageCountScope = domain.PageCountScopeChapter
+	assert.Equal(t, "Chapter 3 - Page 2 of 9", p.footerText(file, 2, 142))
+
+	p.config.PageCountScope = domain.PageCountScopeNone
+	assert.Equal(t, "Chapter 3 - Page 17", p.footerText(file, 2, 142))
+}
+
+func TestFooterTextFromTemplate(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.FooterTemplate = "{course}: {chapterTitle} ({file}) {chapterPage}/{chapterPages}"
+	cfg.FooterTemplateEven = "{page} | {chapter}"
+	cfg.Course = "Go Basics"
+	p := NewProcessor(cfg)
+	file := SingleFileToProcess{SourcePath: "/handout/03-errors.pdf", ChapterNr: 3, ChapterTitle: "errors", PageCount: 9, FirstPageNr: 16}
+
+	assert.Equal(t, "16 | 3", p.footerText(file, 1, 142))
+	assert.Equal(t, "Go Basics: errors (03-errors.pdf) 2/9", p.footerText(file, 2, 142))
+}
+
+func TestFooterDescriptionFollowsPosition(t *testing.T) {
+	p := NewProcessor(domain.NewDefaultEnglishConfig())
+	assert.Contains(t, p.footerDescription(2), "position: bl, offset: 20 6")
+	assert.Contains(t, p.footerDescription(3), "position: br, offset: -20 6")
+
+	p.config.FooterPosition = domain.FooterPositionTopInner
+	p.config.FooterOffsetX, p.config.FooterOffsetY = 30, 10
+	assert.Contains(t, p.footerDescription(2), "position: tr, offset: -30 -10")
+	assert.Contains(t, p.footerDescription(3), "position: tl, offset: 30 -10")
+
+	p.config.FooterPosition = domain.FooterPositionBottomCenter
+	assert.Contains(t, p.footerDescription(2), "position: bc, offset: 0 10")
+	assert.Contains(t, p.footerDescription(3), "position: bc, offset: 0 10")
+}
+
+func TestFooterDescriptionUsesFontAndColor(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.FooterFont, cfg.FooterFontSize, cfg.FooterColor, cfg.FooterOpacity = "Times-Roman", 10, "#FF0000", 0.5
+	description := NewProcessor(cfg).footerDescription(1)
+
+	assert.Contains(t, description, "font:Times-Roman, points:10")
+	assert.Contains(t, description, "color: #FF0000, opacity: 0.5")
+}
+
+func TestRunWithTranslucentFooter(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.RunningHeader = "Handout"
+	cfg.FooterPosition = domain.FooterPositionTopCenter
+	cfg.FooterOpacity = 0.4
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+	for _, file := range result.Files {
+		assert.NoError(t, api.ValidateFile(file.OutputPath, nil), file.OutputPath)
+	}
+}
+
+func TestTotalPageCountIncludesBlankPages(t *testing.T) {
+	files := []SingleFileToProcess{{PageCount: 2}, {PageCount: 4}, {PageCount: 12}}
+
+	assert.Equal(t, 18, totalPageCount(len(files), files))
+}
+
+func TestInvalidPDFsAreReportedAsSkipped(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "FourFilesTwoPdfs"
+	cfg.TargetDir = t.TempDir()
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+
+	assert.Len(t, result.Files, 2)
+	assert.Len(t, result.Skipped, 1)
+	assert.Equal(t, filepath.Join(cfg.SourceDir, "md-disguised-as-pdf.pdf"), result.Skipped[0].Filename)
+	assert.Contains(t, result.Skipped[0].Reason, "not a valid PDF")
+}
+
+func TestStrictModeAbortsBeforeAnyOutput(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "FourFilesTwoPdfs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Strict = true
+
+	_, err := NewProcessor(cfg).Run(context.Background())
+	assert.True(t, errors.Is(err, domain.ErrInvalidPDF))
+
+	entries, err := os.ReadDir(cfg.TargetDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPlanChapters(t *testing.T) {
+	p := NewProcessor(domain.NewDefaultEnglishConfig())
+	files := []SingleFileToProcess{{PageCount: 3}, {PageCount: 4}, {PageCount: 1}}
+
+	p.PlanChapters(len(files), files)
+
+	assert.Equal(t, []int{1, 0, 1}, []int{files[0].BlankPagesAdded, files[1].BlankPagesAdded, files[2].BlankPagesAdded})
+	assert.Equal(t, 3, files[2].ChapterNr)
+	assert.Equal(t, 5, files[1].FirstPageNr)
+	assert.Equal(t, 8, files[1].LastPageNr)
+	assert.Equal(t, 10, files[2].LastPageNr)
+}
+
+func TestPlanChaptersWithoutEvenify(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.Evenify = false
+	files := []SingleFileToProcess{{PageCount: 3}, {PageCount: 1}}
+
+	NewProcessor(cfg).PlanChapters(len(files), files)
+
+	assert.Zero(t, files[0].BlankPagesAdded)
+	assert.Equal(t, 4, files[1].FirstPageNr)
+	assert.Equal(t, 4, files[1].LastPageNr)
+}
@@ -3,47 +3,160 @@ package pdf
 import (
 	"fmt"
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
-	"github.com/rs/zerolog/log"
-	"pdfminion/internal/util"
-	"strconv"
+	"pdfminion/internal/domain"
+	"sort"
+	"time"
 )
 
-// TODO: Split Evenify function into loop-over-all-files and evenify-single-file
+const (
+	// built-in font, replaced by the font file if configured
+	blankPageFont        = "Helvetica"
+	blankPageDescription = "points:48, col: 0.5 0.6 0.5, rot:45, sc:1 abs"
+)
 
-func Evenify(nrOfValidPDFs int, pdfFiles []SingleFileToProcess) {
-	relaxedConf := model.NewDefaultConfiguration()
-	relaxedConf.ValidationMode = model.ValidationRelaxed
+// processFile applies all stages to the in-memory context of a single file:
+// blank pages, footer, running header, personal touch, page labels and chapter bookmark.
+// The result is written to the output directory once.
+// It returns an error wrapping domain.ErrWatermarkFailed if the file cannot be stamped.
+func (p *Processor) processFile(file *SingleFileToProcess, totalPageCount int, personalTouch *personalTouch) error {
+	ctx := file.ctx
+	started := time.Now()
+	// release the context as soon as possible, it holds the complete file
+	defer func() {
+		file.ctx = nil
+		file.duration = time.Since(started)
+	}()
 
-	for i := 0; i < nrOfValidPDFs; i++ {
-		if !util.IsEven(pdfFiles[i].PageCount) {
-			// add single blank page at the end of the file
-			_ = api.InsertPagesFile(pdfFiles[i].Filename, "", []string{strconv.Itoa(pdfFiles[i].PageCount)}, false, relaxedConf)
+	p.logger.Debug().Str("file", file.Filename).Msg("Processing file")
 
-			pdfFiles[i].PageCount++
+	if err := p.evenify(ctx, file); err != nil {
+		return err
+	}
 
-			onTop := true
-			update := false
+	wmcs, err := p.textWatermarksForFile(*file, totalPageCount)
+	if err != nil {
+		return fmt.Errorf("%w: footer and header for %s: %v", domain.ErrWatermarkFailed, file.Filename, err)
+	}
+	if err := addTextWatermarks(ctx, wmcs); err != nil {
+		p.logger.Error().Err(err).Str("file", file.Filename).Msg("Error adding watermarks")
+		return fmt.Errorf("%w: page numbers in %s: %v", domain.ErrWatermarkFailed, file.Filename, err)
+	}
 
-			wm, err := api.TextWatermark(appConfig.BlankPageText, "font:Helvetica, points:48, col: 0.5 0.6 0.5, rot:45, sc:1 abs",
-				onTop, update, types.POINTS)
-			if err != nil {
-				log.Printf("Error creating watermark configuration %v: %v\n", wm, err)
-			} else {
+	// the pages with a personal touch are selected by chapter page numbers, front and back matter have none
+	if personalTouch != nil && !file.isMatter() {
+		if err := p.addPersonalTouch(ctx, *file, personalTouch); err != nil {
+			p.logger.Error().Err(err).Str("file", file.Filename).Msg("Error adding personal touch")
+			return fmt.Errorf("%w: personal touch in %s: %v", domain.ErrWatermarkFailed, file.Filename, err)
+		}
+	}
 
-				err = api.AddWatermarksFile(pdfFiles[i].Filename, "", []string{strconv.Itoa(pdfFiles[i].PageCount)}, wm,
-					relaxedConf)
+	if err := p.addChapterPageLabels(ctx, *file); err != nil {
+		p.logger.Error().Err(err).Str("file", file.Filename).Msg("Error adding page labels")
+		return fmt.Errorf("error adding page labels to %s: %w", file.Filename, err)
+	}
 
-				if err != nil {
-					log.Printf("error stamping blank page in file %v: %v\n", pdfFiles[i].Filename, err)
-				}
+	// a temporary file is written first, so an interrupted write never leaves a partial file
+	if err := writeContextFile(ctx, file.Filename); err != nil {
+		return fmt.Errorf("error writing %s: %w", file.Filename, err)
+	}
+	file.written = true
+	return nil
+}
 
+// evenify appends and inserts the blank pages determined by PlanChapters
+func (p *Processor) evenify(ctx *model.Context, file *SingleFileToProcess) error {
+	if file.BlankPagesAdded == 0 && file.BlankPagesBefore == 0 {
+		return nil
+	}
+
+	originalPageCount := file.PageCount - file.BlankPagesAdded - file.BlankPagesBefore
+	for pageNr := originalPageCount; pageNr < originalPageCount+file.BlankPagesAdded; pageNr++ {
+		// add single blank page after pageNr
+		if err := ctx.InsertBlankPages(types.IntSet{pageNr: true}, false); err != nil {
+			return fmt.Errorf("error adding blank page to %s: %w", file.Filename, err)
+		}
+	}
+	for i := 0; i < file.BlankPagesBefore; i++ {
+		if err := ctx.InsertBlankPages(types.IntSet{1: true}, true); err != nil {
+			return fmt.Errorf("error inserting blank page into %s: %w", file.Filename, err)
+		}
+	}
+	// pdfcpu updates the page tree, but not the page count
+	ctx.PageCount = file.PageCount
+
+	p.logger.Debug().Str("file", file.Filename).Msg("was evenified")
+	return nil
+}
+
+// textWatermarksForFile combines footer, running header and blank page text,
+// so all of them are stamped in a single pass
+func (p *Processor) textWatermarksForFile(file SingleFileToProcess, totalPageCount int) (map[int][]*model.Watermark, error) {
+	wmcs := make(map[int][]*model.Watermark)
+
+	if file.isNumbered() {
+		footers := p.watermarkConfigurationForFile(file, totalPageCount)
+		for page, wm := range footers {
+			wmcs[page] = append(wmcs[page], wm)
+		}
+	}
+
+	if p.hasRunningHeader(file) {
+		texts := make([]string, file.PageCount)
+		for page := 1; page <= file.PageCount; page++ {
+			header := p.runningHeader(file, file.FirstPageNr-1+page)
+			texts[page-1] = domain.RenderTemplate(header, p.templateValues(file, page, totalPageCount))
+		}
+		headers := headerConfigurationForFile(p.stampFont(headerFont), texts, file.FirstPageNr-1)
+		for page, wm := range headers {
+			wmcs[page] = append(wmcs[page], wm)
+		}
+	}
+
+	blankPage := "font:" + p.stampFont(blankPageFont) + ", " + blankPageDescription
+	blankPages := make([]int, 0, file.BlankPagesBefore+file.BlankPagesAdded)
+	for page := 1; page <= file.BlankPagesBefore; page++ {
+		blankPages = append(blankPages, page)
+	}
+	for page := file.PageCount - file.BlankPagesAdded + 1; page <= file.PageCount; page++ {
+		blankPages = append(blankPages, page)
+	}
+	for _, page := range blankPages {
+		wm, err := api.TextWatermark(p.config.BlankPageText, blankPage, true, false, types.POINTS)
+		if err != nil {
+			return nil, fmt.Errorf("blank page text: %w", err)
+		}
+		wmcs[page] = append(wmcs[page], wm)
+	}
+	return wmcs, nil
+}
+
+// addTextWatermarks stamps all text watermarks. pdfcpu applies the opacity of an arbitrary watermark
+// to all watermarks of a single pass, so watermarks with different opacities are stamped in separate passes.
+func addTextWatermarks(ctx *model.Context, wmcs map[int][]*model.Watermark) error {
+	byOpacity := make(map[float64]map[int][]*model.Watermark)
+	for page, wms := range wmcs {
+		for _, wm := range wms {
+			if byOpacity[wm.Opacity] == nil {
+				byOpacity[wm.Opacity] = make(map[int][]*model.Watermark)
 			}
-			if appConfig.Verbose {
-				fmt.Printf("File %s was evenified\n", pdfFiles[i].Filename)
-			}
-			log.Debug().Str("File %s\n", pdfFiles[i].Filename).Msg("was evenified")
+			byOpacity[wm.Opacity][page] = append(byOpacity[wm.Opacity][page], wm)
+		}
+	}
+
+	// a fixed order of passes keeps the output deterministic
+	opacities := make([]float64, 0, len(byOpacity))
+	for opacity := range byOpacity {
+		opacities = append(opacities, opacity)
+	}
+	sort.Float64s(opacities)
+
+	for _, opacity := range opacities {
+		if err := pdfcpu.AddWatermarksSliceMap(ctx, byOpacity[opacity]); err != nil {
+			return err
 		}
 	}
+	return nil
 }
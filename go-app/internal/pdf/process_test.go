@@ -0,0 +1,229 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"pdfminion/internal/util"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// benchmarkSourceDir creates a source directory containing only the given sample files
+func benchmarkSourceDir(b *testing.B, fileNames ...string) string {
+	b.Helper()
+	dir := b.TempDir()
+	for _, fileName := range fileNames {
+		content, err := os.ReadFile(filepath.Join(sampleDir, fileName))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fileName), content, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func benchmarkRun(b *testing.B, configure func(cfg *domain.MinionConfig)) {
+	sourceDir := benchmarkSourceDir(b, "sample-A4-portrait-101pgs.pdf")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cfg := domain.NewDefaultEnglishConfig()
+		cfg.SourceDir = sourceDir
+		cfg.TargetDir = b.TempDir()
+		configure(&cfg)
+		b.StartTimer()
+
+		if _, err := NewProcessor(cfg).Run(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRun101Pages numbers and evenifies a single 101 page deck
+func BenchmarkRun101Pages(b *testing.B) {
+	benchmarkRun(b, func(cfg *domain.MinionConfig) {})
+}
+
+// BenchmarkRun101PagesAllStages additionally adds running header and personal touch
+func BenchmarkRun101PagesAllStages(b *testing.B) {
+	benchmarkRun(b, func(cfg *domain.MinionConfig) {
+		cfg.RunningHeader = "Benchmark"
+		cfg.PersonalTouch = true
+	})
+}
+
+// BenchmarkRun101PagesFileBased numbers and evenifies the same deck like the previous pipeline did,
+// for comparison with BenchmarkRun101Pages: the file is copied, then rewritten by pdfcpu's file API
+// for every stage, each call re-reading and re-validating the whole PDF
+func BenchmarkRun101PagesFileBased(b *testing.B) {
+	sourceDir := benchmarkSourceDir(b, "sample-A4-portrait-101pgs.pdf")
+	sourceFile := filepath.Join(sourceDir, "sample-A4-portrait-101pgs.pdf")
+	p := NewProcessor(domain.NewDefaultEnglishConfig())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		targetFile := filepath.Join(b.TempDir(), filepath.Base(sourceFile))
+		b.StartTimer()
+
+		// validate and count pages
+		if err := api.ValidateFile(sourceFile, p.relaxedConf); err != nil {
+			b.Fatal(err)
+		}
+		pageCount, err := api.PageCountFile(sourceFile)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		// copy
+		content, err := os.ReadFile(sourceFile)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(targetFile, content, 0644); err != nil {
+			b.Fatal(err)
+		}
+
+		// evenify: append a blank page and stamp the blank page text
+		if err := api.InsertPagesFile(targetFile, "", []string{strconv.Itoa(pageCount)}, false, p.relaxedConf); err != nil {
+			b.Fatal(err)
+		}
+		pageCount++
+		wm, err := api.TextWatermark(p.config.BlankPageText, "font:"+blankPageFont+", "+blankPageDescription, true, false, types.POINTS)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := api.AddWatermarksFile(targetFile, "", []string{strconv.Itoa(pageCount)}, wm, p.relaxedConf); err != nil {
+			b.Fatal(err)
+		}
+
+		// page numbers
+		file := SingleFileToProcess{SourcePath: sourceFile, ChapterNr: 1, PageCount: pageCount, FirstPageNr: 1, LastPageNr: pageCount}
+		if err := api.AddWatermarksMapFile(targetFile, "", p.watermarkConfigurationForFile(file, pageCount), p.relaxedConf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRunWritesValidEvenifiedFiles(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwelvePDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.RunningHeader = "Header"
+	cfg.PersonalTouch = true
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, result.Files, 11)
+
+	for _, file := range result.Files {
+		assert.NoError(t, api.ValidateFile(file.OutputPath, nil), file.OutputPath)
+
+		pageCount, err := api.PageCountFile(file.OutputPath)
+		assert.NoError(t, err)
+		assert.Equal(t, file.LastPageNr-file.FirstPageNr+1, pageCount, file.OutputPath)
+		assert.True(t, util.IsEven(pageCount), file.OutputPath)
+	}
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(cfg.TargetDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 11)
+}
+
+// TestConcurrentRunIsDeterministic processes the same files with one and with many workers.
+// Run it with the race detector: go test -race -run Concurrent ./internal/pdf/
+func TestConcurrentRunIsDeterministic(t *testing.T) {
+	run := func(jobs int) *Result {
+		cfg := domain.NewDefaultEnglishConfig()
+		cfg.SourceDir = sampleDir + "TwelvePDFs"
+		cfg.TargetDir = t.TempDir()
+		cfg.RunningHeader = "Header"
+		cfg.PersonalTouch = true
+		cfg.Merge = true
+		cfg.TOC = true
+		cfg.Jobs = jobs
+
+		result, err := NewProcessor(cfg).Run(context.Background())
+		assert.NoError(t, err)
+
+		// make results of different target directories comparable
+		for i := range result.Files {
+			result.Files[i].OutputPath = filepath.Base(result.Files[i].OutputPath)
+		}
+		result.TOCFile = filepath.Base(result.TOCFile)
+		result.MergedFile = filepath.Base(result.MergedFile)
+		return result
+	}
+
+	sequential := run(1)
+	concurrent := run(8)
+
+	assert.Equal(t, sequential, concurrent)
+	for i, file := range concurrent.Files {
+		assert.Equal(t, i+1, file.ChapterNr)
+	}
+}
+
+func TestCancelledRunLeavesTargetUnchanged(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwelvePDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Jobs = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// interrupt as soon as the first chapter has been written to the staging directory,
+	// the fourth chapter (101 pages) takes long enough to be interrupted.
+	// Polling stops when the run has finished, at the latest after a minute.
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		deadline := time.After(time.Minute)
+		for {
+			select {
+			case <-done:
+				return
+			case <-deadline:
+				cancel()
+				return
+			case <-ticker.C:
+				if staged, _ := filepath.Glob(filepath.Join(filepath.Dir(cfg.TargetDir), ".*", "01_*.pdf")); len(staged) > 0 {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	result, err := NewProcessor(cfg).Run(ctx)
+	close(done)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.NotEmpty(t, result.Files)
+	assert.Less(t, len(result.Files), 11)
+
+	for i, file := range result.Files {
+		assert.Equal(t, i+1, file.ChapterNr)
+		assert.Empty(t, file.OutputPath)
+	}
+
+	// neither target nor staging directory contain any files
+	entries, err := os.ReadDir(filepath.Dir(cfg.TargetDir))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	entries, err = os.ReadDir(cfg.TargetDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,336 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// report is the machine-readable summary of a run, written if Report is configured
+type report struct {
+	Tool                  string          `json:"tool"`
+	Version               string          `json:"version"`
+	Started               time.Time       `json:"started"`
+	DurationMs            int64           `json:"durationMs"`
+	Config                reportConfig    `json:"config"`
+	TotalPageCount        int             `json:"totalPageCount"`
+	TOCFile               string          `json:"tocFile,omitempty"`
+	MergedFile            string          `json:"mergedFile,omitempty"`
+	MergedBlankPagesAdded int             `json:"mergedBlankPagesAdded"` // appended to the merged file, see EvenifyScope
+	Chapters              []reportChapter `json:"chapters"`
+	Warnings              []string        `json:"warnings"`
+}
+
+type reportChapter struct {
+	ChapterNr              int    `json:"chapterNr"`
+	ChapterTitle           string `json:"chapterTitle"`
+	Matter                 string `json:"matter,omitempty"` // front or back matter, chapterNr is 0
+	SourcePath             string `json:"sourcePath"`
+	OutputPath             string `json:"outputPath"`
+	InputByteCount         int64  `json:"inputByteCount"`
+	OriginalPageCount      int    `json:"originalPageCount"`
+	PageCount              int    `json:"pageCount"`
+	BlankPagesBefore       int    `json:"blankPagesBefore"`
+	BlankPagesAdded        int    `json:"blankPagesAdded"`
+	MergedBlankPagesBefore int    `json:"mergedBlankPagesBefore"` // inserted in the merged file only, see EvenifyScope
+	Evenified              bool   `json:"evenified"`
+	FirstPageNr            int    `json:"firstPageNr"`
+	LastPageNr             int    `json:"lastPageNr"`
+	DurationMs             int64  `json:"durationMs"`
+}
+
+// reportConfig contains the settings of a run that determine its output
+type reportConfig struct {
+	Language             string   `json:"language"`
+	SourceDir            string   `json:"sourceDir"`
+	TargetDir            string   `json:"targetDir"`
+	Recursive            bool     `json:"recursive"`
+	Include              []string `json:"include"`
+	Exclude              []string `json:"exclude"`
+	FrontMatter          []string `json:"frontMatter"`
+	BackMatter           []string `json:"backMatter"`
+	MatterNumbering      string   `json:"matterNumbering"`
+	Order                string   `json:"order"`
+	Manifest             string   `json:"manifest,omitempty"`
+	Handout              string   `json:"handout,omitempty"`
+	Strict               bool     `json:"strict"`
+	Evenify              bool     `json:"evenify"`
+	EvenifyPolicy        string   `json:"evenifyPolicy"`
+	EvenifyScope         string   `json:"evenifyScope"`
+	Merge                bool     `json:"merge"`
+	MergeFileName        string   `json:"mergeFileName"`
+	TOC                  bool     `json:"toc"`
+	TOCTitle             string   `json:"tocTitle"`
+	Report               string   `json:"report"`
+	RunningHeader        string   `json:"runningHeader"`
+	RunningHeaderEven    string   `json:"runningHeaderEven,omitempty"`
+	RunningHeaderOdd     string   `json:"runningHeaderOdd,omitempty"`
+	ChapterPrefix        string   `json:"chapterPrefix"`
+	Separator            string   `json:"separator"`
+	PageNrPrefix         string   `json:"pageNrPrefix"`
+	PageCountPrefix      string   `json:"pageCountPrefix"`
+	PageCountScope       string   `json:"pageCountScope"`
+	BlankPageText        string   `json:"blankPageText"`
+	FooterTemplate       string   `json:"footerTemplate"`
+	FooterTemplateEven   string   `json:"footerTemplateEven,omitempty"`
+	FooterTemplateOdd    string   `json:"footerTemplateOdd,omitempty"`
+	Course               string   `json:"course,omitempty"`
+	ChapterNumberStyle   string   `json:"chapterNumberStyle"`
+	PageNumberStyle      string   `json:"pageNumberStyle"`
+	PageNumbering        string   `json:"pageNumbering"`
+	ChapterPageSeparator string   `json:"chapterPageSeparator"`
+	FirstChapter         int      `json:"firstChapter"`
+	FirstPage            int      `json:"firstPage"`
+	ContinueFrom         string   `json:"continueFrom,omitempty"`
+	FooterPosition       string   `json:"footerPosition"`
+	FooterOffsetX        int      `json:"footerOffsetX"`
+	FooterOffsetY        int      `json:"footerOffsetY"`
+	FooterFont           string   `json:"footerFont"`
+	FooterFontSize       int      `json:"footerFontSize"`
+	FooterColor          string   `json:"footerColor"`
+	FooterOpacity        float64  `json:"footerOpacity"`
+	FontFile             string   `json:"fontFile,omitempty"`
+	PersonalTouch        bool     `json:"personalTouch"`
+	PersonalTouchImage   string   `json:"personalTouchImage,omitempty"`
+	PersonalTouchDensity int      `json:"personalTouchDensity"`
+	PersonalTouchSeed    int64    `json:"personalTouchSeed"`
+}
+
+// newReportConfig takes the settings of the report from cfg, internal state like the validation results is left out
+func newReportConfig(cfg domain.MinionConfig) reportConfig {
+	return reportConfig{
+		Language:             cfg.Language.String(),
+		SourceDir:            cfg.SourceDir,
+		TargetDir:            cfg.TargetDir,
+		Recursive:            cfg.Recursive,
+		Include:              cfg.Include,
+		Exclude:              cfg.Exclude,
+		FrontMatter:          cfg.FrontMatter,
+		BackMatter:           cfg.BackMatter,
+		MatterNumbering:      cfg.MatterNumbering,
+		Order:                cfg.Order,
+		Manifest:             cfg.Manifest,
+		Handout:              cfg.Handout,
+		Strict:               cfg.Strict,
+		Evenify:              cfg.Evenify,
+		EvenifyPolicy:        cfg.EvenifyPolicy,
+		EvenifyScope:         cfg.EvenifyScope,
+		Merge:                cfg.Merge,
+		MergeFileName:        cfg.MergeFileName,
+		TOC:                  cfg.TOC,
+		TOCTitle:             cfg.TOCTitle,
+		Report:               cfg.Report,
+		RunningHeader:        cfg.RunningHeader,
+		RunningHeaderEven:    cfg.RunningHeaderEven,
+		RunningHeaderOdd:     cfg.RunningHeaderOdd,
+		ChapterPrefix:        cfg.ChapterPrefix,
+		Separator:            cfg.Separator,
+		PageNrPrefix:         cfg.PageNrPrefix,
+		PageCountPrefix:      cfg.PageCountPrefix,
+		PageCountScope:       cfg.PageCountScope,
+		BlankPageText:        cfg.BlankPageText,
+		FooterTemplate:       cfg.FooterTemplate,
+		FooterTemplateEven:   cfg.FooterTemplateEven,
+		FooterTemplateOdd:    cfg.FooterTemplateOdd,
+		Course:               cfg.Course,
+		ChapterNumberStyle:   cfg.ChapterNumberStyle,
+		PageNumberStyle:      cfg.PageNumberStyle,
+		PageNumbering:        cfg.PageNumbering,
+		ChapterPageSeparator: cfg.ChapterPageSeparator,
+		FirstChapter:         cfg.FirstChapter,
+		FirstPage:            cfg.FirstPage,
+		ContinueFrom:         cfg.ContinueFrom,
+		FooterPosition:       cfg.FooterPosition,
+		FooterOffsetX:        cfg.FooterOffsetX,
+		FooterOffsetY:        cfg.FooterOffsetY,
+		FooterFont:           cfg.FooterFont,
+		FooterFontSize:       cfg.FooterFontSize,
+		FooterColor:          cfg.FooterColor,
+		FooterOpacity:        cfg.FooterOpacity,
+		FontFile:             cfg.FontFile,
+		PersonalTouch:        cfg.PersonalTouch,
+		PersonalTouchImage:   cfg.PersonalTouchImage,
+		PersonalTouchDensity: cfg.PersonalTouchDensity,
+		PersonalTouchSeed:    cfg.PersonalTouchSeed,
+	}
+}
+
+// csvHeader names the columns of CSV reports, which contain one row per chapter
+var csvHeader = []string{"chapter_nr", "chapter_title", "source_path", "output_path", "input_bytes",
+	"matter", "original_pages", "pages", "blank_pages_before", "blank_pages_added", "merged_blank_pages_before",
+	"evenified", "first_page", "last_page", "duration_ms"}
+
+// writeReport writes the report of result to the staging directory and returns its path.
+// All paths in the report are the final paths within the target directory.
+func (p *Processor) writeReport(stage *staging, result *Result, pdfFiles []SingleFileToProcess, started time.Time) (string, error) {
+	r := report{
+		Tool:           "PDFminion",
+		Version:        domain.AppVersion(),
+		Started:        started,
+		DurationMs:     time.Since(started).Milliseconds(),
+		Config:         newReportConfig(p.config),
+		TotalPageCount: result.TotalPageCount,
+		Chapters:       make([]reportChapter, 0, len(pdfFiles)),
+		Warnings:       make([]string, 0, len(result.Skipped)+len(result.Warnings)),
+	}
+	if result.TOCFile != "" {
+		r.TOCFile = stage.targetPath(result.TOCFile)
+	}
+	if result.MergedFile != "" {
+		r.MergedFile = stage.targetPath(result.MergedFile)
+		r.MergedBlankPagesAdded = result.MergedBlankPagesAdded
+	}
+	for _, file := range pdfFiles {
+		r.Chapters = append(r.Chapters, reportChapter{
+			ChapterNr:              file.ChapterNr,
+			ChapterTitle:           file.ChapterTitle,
+			Matter:                 file.Matter,
+			SourcePath:             file.SourcePath,
+			OutputPath:             stage.targetPath(file.Filename),
+			InputByteCount:         file.OrigByteCount,
+			OriginalPageCount:      file.PageCount - file.BlankPagesAdded - file.BlankPagesBefore,
+			PageCount:              file.PageCount,
+			BlankPagesBefore:       file.BlankPagesBefore,
+			BlankPagesAdded:        file.BlankPagesAdded,
+			MergedBlankPagesBefore: file.mergedBlankPagesBefore,
+			Evenified:              file.BlankPagesAdded > 0 || file.BlankPagesBefore > 0 || file.mergedBlankPagesBefore > 0,
+			FirstPageNr:            file.FirstPageNr,
+			LastPageNr:             file.LastPageNr,
+			DurationMs:             file.duration.Milliseconds(),
+		})
+	}
+	for _, skipped := range result.Skipped {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("skipped %s: %s", skipped.Filename, skipped.Reason))
+	}
+	r.Warnings = append(r.Warnings, result.Warnings...)
+
+	reportFile := filepath.Join(stage.dir, p.config.Report)
+	if err := os.MkdirAll(filepath.Dir(reportFile), os.ModePerm); err != nil {
+		return "", err
+	}
+	f, err := os.Create(reportFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(reportFile), domain.ReportFormatCSV) {
+		err = r.writeCSV(f)
+	} else {
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(r)
+	}
+	if err != nil {
+		return "", err
+	}
+	return reportFile, f.Close()
+}
+
+// writeCSV writes one row per chapter, version, configuration, warnings and the blank pages appended to the merged file
+// are only part of JSON reports
+func (r report) writeCSV(f *os.File) error {
+	w := csv.NewWriter(f)
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, c := range r.Chapters {
+		record := []string{
+			strconv.Itoa(c.ChapterNr), c.ChapterTitle, c.SourcePath, c.OutputPath,
+			strconv.FormatInt(c.InputByteCount, 10), c.Matter, strconv.Itoa(c.OriginalPageCount), strconv.Itoa(c.PageCount),
+			strconv.Itoa(c.BlankPagesBefore), strconv.Itoa(c.BlankPagesAdded), strconv.Itoa(c.MergedBlankPagesBefore),
+			strconv.FormatBool(c.Evenified), strconv.Itoa(c.FirstPageNr), strconv.Itoa(c.LastPageNr), strconv.FormatInt(c.DurationMs, 10),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// continueNumbering sets first chapter and page after the last chapter of a previous run's report,
+// so e.g. the second volume of a handout continues the numbering of the first one
+func (p *Processor) continueNumbering(reportFile string) error {
+	lastChapterNr, lastPageNr, err := readReportEnd(reportFile)
+	if err != nil {
+		return fmt.Errorf("%w: report %s cannot be continued: %v", domain.ErrInvalidConfig, reportFile, err)
+	}
+	p.config.FirstChapter = lastChapterNr + 1
+	p.config.FirstPage = lastPageNr + 1
+	p.logger.Debug().Str("report", reportFile).Int("firstChapter", p.config.FirstChapter).Int("firstPage", p.config.FirstPage).Msg("Continuing numbering")
+	return nil
+}
+
+// readReportEnd returns chapter number and last page number of the last chapter of a JSON or CSV report,
+// front and back matter are ignored
+func readReportEnd(reportFile string) (lastChapterNr, lastPageNr int, err error) {
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var chapters []reportChapter
+	if strings.EqualFold(filepath.Ext(reportFile), domain.ReportFormatCSV) {
+		chapters, err = readCSVChapters(data)
+	} else {
+		var r struct {
+			Chapters []reportChapter `json:"chapters"`
+		}
+		err = json.Unmarshal(data, &r)
+		chapters = r.Chapters
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	for i := len(chapters) - 1; i >= 0; i-- {
+		if chapters[i].Matter == "" {
+			return chapters[i].ChapterNr, chapters[i].LastPageNr, nil
+		}
+	}
+	return 0, 0, errors.New("the report contains no chapters")
+}
+
+// readCSVChapters reads chapter numbers and page ranges from the rows of a CSV report
+func readCSVChapters(data []byte) ([]reportChapter, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("the report is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	chapters := make([]reportChapter, 0, len(records)-1)
+	for _, record := range records[1:] {
+		var chapter reportChapter
+		for name, value := range map[string]*int{"chapter_nr": &chapter.ChapterNr, "first_page": &chapter.FirstPageNr, "last_page": &chapter.LastPageNr} {
+			i, ok := columns[name]
+			if !ok {
+				return nil, fmt.Errorf("column %s is missing", name)
+			}
+			if *value, err = strconv.Atoi(record[i]); err != nil {
+				return nil, fmt.Errorf("invalid %s %q", name, record[i])
+			}
+		}
+		i, ok := columns["matter"]
+		if !ok {
+			return nil, errors.New("column matter is missing")
+		}
+		chapter.Matter = record[i]
+		chapters = append(chapters, chapter)
+	}
+	return chapters, nil
+}
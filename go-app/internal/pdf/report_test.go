@@ -0,0 +1,152 @@
+package pdf
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"testing"
+)
+
+func runWithReport(t *testing.T, reportFile string) (domain.MinionConfig, *Result) {
+	t.Helper()
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "FourFilesTwoPdfs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Merge = true
+	cfg.Report = reportFile
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(cfg.TargetDir, reportFile), result.ReportFile)
+	return cfg, result
+}
+
+func TestJSONReport(t *testing.T) {
+	cfg, result := runWithReport(t, "report.json")
+
+	content, err := os.ReadFile(result.ReportFile)
+	assert.NoError(t, err)
+	var r report
+	assert.NoError(t, json.Unmarshal(content, &r))
+
+	assert.Equal(t, domain.AppVersion(), r.Version)
+	assert.Equal(t, cfg.SourceDir, r.Config.SourceDir)
+	assert.Equal(t, 6, r.TotalPageCount)
+	assert.Equal(t, result.MergedFile, r.MergedFile)
+	assert.Len(t, r.Warnings, 1)
+	assert.Contains(t, r.Warnings[0], "md-disguised-as-pdf.pdf")
+
+	assert.Len(t, r.Chapters, 2)
+	for i, chapter := range r.Chapters {
+		assert.Equal(t, result.Files[i].OutputPath, chapter.OutputPath)
+		assert.FileExists(t, chapter.OutputPath)
+		assert.Equal(t, result.Files[i].InputByteCount, chapter.InputByteCount)
+		assert.Positive(t, chapter.InputByteCount)
+		assert.True(t, chapter.Evenified)
+	}
+	assert.Equal(t, 3, r.Chapters[1].OriginalPageCount)
+	assert.Equal(t, 4, r.Chapters[1].PageCount)
+
+	// the configuration contains user-facing settings with camelCase keys only
+	var keys struct {
+		Config map[string]interface{} `json:"config"`
+	}
+	assert.NoError(t, json.Unmarshal(content, &keys))
+	for _, key := range []string{"language", "sourceDir", "targetDir", "chapterPrefix", "evenifyPolicy", "mergeFileName", "toc"} {
+		assert.Contains(t, keys.Config, key)
+	}
+	for key := range keys.Config {
+		assert.Regexp(t, "^[a-z][a-zA-Z]*$", key)
+		assert.NotContains(t, key, "Valid")
+	}
+	assert.NotContains(t, keys.Config, "setFields")
+	assert.NotContains(t, keys.Config, "verbose")
+}
+
+func TestCSVReport(t *testing.T) {
+	_, result := runWithReport(t, "reports/run.csv")
+
+	f, err := os.Open(result.ReportFile)
+	assert.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	assert.NoError(t, err)
+
+	assert.Len(t, records, 3)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{"2", "sample A4 portrait 3pgs"}, records[2][:2])
+	assert.Equal(t, result.Files[1].OutputPath, records[2][3])
+}
+
+func TestReportWithMergedScope(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Merge = true
+	cfg.EvenifyPolicy = domain.EvenifyPolicyStartRight
+	cfg.EvenifyScope = domain.EvenifyScopeMerged
+	cfg.Report = "report.json"
+
+	result, err := NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+	content, err := os.ReadFile(result.ReportFile)
+	assert.NoError(t, err)
+	var r report
+	assert.NoError(t, json.Unmarshal(content, &r))
+
+	// the second chapter would start on page 2, the merged file gets a blank page before it, but none at its end
+	assert.Equal(t, 1, r.Chapters[1].MergedBlankPagesBefore)
+	assert.Zero(t, r.Chapters[1].BlankPagesBefore)
+	assert.True(t, r.Chapters[1].Evenified)
+	assert.Zero(t, r.MergedBlankPagesAdded)
+}
+
+func TestContinueFromReport(t *testing.T) {
+	for _, reportFile := range []string{"report.json", "reports/run.csv"} {
+		_, previous := runWithReport(t, reportFile)
+
+		cfg := domain.NewDefaultEnglishConfig()
+		cfg.SourceDir = sampleDir + "FourFilesTwoPdfs"
+		cfg.TargetDir = t.TempDir()
+		cfg.ContinueFrom = previous.ReportFile
+
+		plan, err := NewProcessor(cfg).Plan(context.Background())
+		assert.NoError(t, err, reportFile)
+		assert.Equal(t, 6, plan.TotalPageCount, reportFile)
+		assert.Equal(t, 3, plan.Chapters[0].ChapterNr, reportFile)
+		assert.Equal(t, 7, plan.Chapters[0].FirstPageNr, reportFile)
+		assert.Equal(t, "Chapter 3 - Page 7 of 12", plan.Chapters[0].Footer, reportFile)
+		assert.Equal(t, 4, plan.Chapters[1].ChapterNr, reportFile)
+		assert.Equal(t, 12, plan.Chapters[1].LastPageNr, reportFile)
+	}
+}
+
+func TestContinueFromReportWithoutChapters(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "FourFilesTwoPdfs"
+	cfg.TargetDir = t.TempDir()
+	cfg.ContinueFrom = filepath.Join(t.TempDir(), "report.json")
+	writeFile(t, cfg.ContinueFrom, `{"tool": "PDFminion", "chapters": []}`)
+
+	_, err := NewProcessor(cfg).Plan(context.Background())
+	assert.ErrorIs(t, err, domain.ErrInvalidConfig)
+	assert.Contains(t, err.Error(), "no chapters")
+}
+
+func TestContinueFromReportIgnoresBackMatter(t *testing.T) {
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	writeFile(t, reportFile, `{"chapters": [
+  {"chapterNr": 0, "matter": "front", "firstPageNr": 1, "lastPageNr": 2},
+  {"chapterNr": 4, "firstPageNr": 1, "lastPageNr": 16},
+  {"chapterNr": 0, "matter": "back", "firstPageNr": 3, "lastPageNr": 4}
+]}`)
+
+	lastChapterNr, lastPageNr, err := readReportEnd(reportFile)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, lastChapterNr)
+	assert.Equal(t, 16, lastPageNr)
+}
@@ -0,0 +1,141 @@
+package pdf
+
+import (
+	"fmt"
+	"github.com/rs/zerolog"
+	"os"
+	"path/filepath"
+)
+
+// staging collects all output in a temporary directory next to the target directory.
+// The target directory is only changed once every file has been processed successfully,
+// so a failed or interrupted run never leaves a mix of processed and unprocessed files behind.
+type staging struct {
+	dir       string
+	targetDir string
+	logger    zerolog.Logger
+}
+
+// files replaced in the target directory are kept here until all staged files have been moved
+const stagingBackupDir = ".replaced"
+
+// newStaging creates the staging directory as a sibling of targetDir,
+// so that files can be moved into the target directory by renaming them
+func newStaging(targetDir string, logger zerolog.Logger) (*staging, error) {
+	targetDir = filepath.Clean(targetDir)
+
+	dir, err := os.MkdirTemp(filepath.Dir(targetDir), "."+filepath.Base(targetDir)+"-pdfminion-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating staging directory next to %s: %w", targetDir, err)
+	}
+	logger.Debug().Str("dir", dir).Msg("Staging directory created")
+
+	return &staging{dir: dir, targetDir: targetDir, logger: logger}, nil
+}
+
+// relPath returns the path of a staged file relative to the staging directory
+func (s *staging) relPath(stagedFile string) string {
+	relPath, err := filepath.Rel(s.dir, stagedFile)
+	if err != nil {
+		return filepath.Base(stagedFile)
+	}
+	return relPath
+}
+
+// targetPath returns the final path of a staged file, sub-folders are kept
+func (s *staging) targetPath(stagedFile string) string {
+	return filepath.Join(s.targetDir, s.relPath(stagedFile))
+}
+
+// commit moves the staged files into the target directory.
+// If moving fails, files already moved are removed, replaced files are restored
+// and sub-folders created in the target directory are removed again.
+func (s *staging) commit(stagedFiles []string) error {
+	type move struct {
+		target string
+		backup string // empty, unless an existing file has been replaced
+	}
+	done := make([]move, 0, len(stagedFiles))
+	var createdDirs []string
+
+	rollback := func() {
+		for i := len(done) - 1; i >= 0; i-- {
+			if err := os.Remove(done[i].target); err != nil {
+				s.logger.Error().Err(err).Str("file", done[i].target).Msg("Error rolling back")
+			}
+			if done[i].backup != "" {
+				if err := os.Rename(done[i].backup, done[i].target); err != nil {
+					s.logger.Error().Err(err).Str("file", done[i].target).Msg("Error restoring replaced file")
+				}
+			}
+		}
+		// created sub-folders before their parents
+		for i := len(createdDirs) - 1; i >= 0; i-- {
+			if err := os.Remove(createdDirs[i]); err != nil {
+				s.logger.Error().Err(err).Str("dir", createdDirs[i]).Msg("Error rolling back")
+			}
+		}
+	}
+
+	for _, stagedFile := range stagedFiles {
+		m := move{target: s.targetPath(stagedFile)}
+
+		dirs, err := mkdirAll(filepath.Dir(m.target))
+		createdDirs = append(createdDirs, dirs...)
+		if err != nil {
+			rollback()
+			return fmt.Errorf("error creating folder in %s: %w", s.targetDir, err)
+		}
+
+		if _, err := os.Stat(m.target); err == nil {
+			m.backup = filepath.Join(s.dir, stagingBackupDir, s.relPath(stagedFile))
+			if err := os.MkdirAll(filepath.Dir(m.backup), os.ModePerm); err != nil {
+				rollback()
+				return fmt.Errorf("error creating backup directory: %w", err)
+			}
+			if err := os.Rename(m.target, m.backup); err != nil {
+				rollback()
+				return fmt.Errorf("error replacing %s: %w", m.target, err)
+			}
+		}
+
+		if err := os.Rename(stagedFile, m.target); err != nil {
+			if m.backup != "" {
+				if restoreErr := os.Rename(m.backup, m.target); restoreErr != nil {
+					s.logger.Error().Err(restoreErr).Str("file", m.target).Msg("Error restoring replaced file")
+				}
+			}
+			rollback()
+			return fmt.Errorf("error moving %s into %s: %w", s.relPath(stagedFile), s.targetDir, err)
+		}
+		done = append(done, m)
+	}
+	return nil
+}
+
+// mkdirAll creates dir including all missing parents and returns the created folders, parents first
+func mkdirAll(dir string) ([]string, error) {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil || d == filepath.Dir(d) {
+			break
+		}
+		missing = append([]string{d}, missing...)
+	}
+
+	created := make([]string, 0, len(missing))
+	for _, d := range missing {
+		if err := os.Mkdir(d, os.ModePerm); err != nil {
+			return created, err
+		}
+		created = append(created, d)
+	}
+	return created, nil
+}
+
+// cleanup removes the staging directory including all files which have not been committed
+func (s *staging) cleanup() {
+	if err := os.RemoveAll(s.dir); err != nil {
+		s.logger.Error().Err(err).Str("dir", s.dir).Msg("Error removing staging directory")
+	}
+}
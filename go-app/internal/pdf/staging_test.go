@@ -0,0 +1,88 @@
+package pdf
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, fileName, content string) {
+	t.Helper()
+	assert.NoError(t, os.WriteFile(fileName, []byte(content), 0644))
+}
+
+func readFile(t *testing.T, fileName string) string {
+	t.Helper()
+	content, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestStagingCommitReplacesFiles(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "target")
+	assert.NoError(t, os.Mkdir(targetDir, os.ModePerm))
+	writeFile(t, filepath.Join(targetDir, "a.pdf"), "old a")
+	writeFile(t, filepath.Join(targetDir, "other.txt"), "unrelated")
+
+	stage, err := newStaging(targetDir, zerolog.Nop())
+	assert.NoError(t, err)
+	defer stage.cleanup()
+	assert.Equal(t, filepath.Dir(targetDir), filepath.Dir(stage.dir), "staging directory is a sibling of the target")
+
+	writeFile(t, filepath.Join(stage.dir, "a.pdf"), "new a")
+	writeFile(t, filepath.Join(stage.dir, "b.pdf"), "new b")
+
+	assert.NoError(t, stage.commit([]string{filepath.Join(stage.dir, "a.pdf"), filepath.Join(stage.dir, "b.pdf")}))
+	assert.Equal(t, "new a", readFile(t, filepath.Join(targetDir, "a.pdf")))
+	assert.Equal(t, "new b", readFile(t, filepath.Join(targetDir, "b.pdf")))
+	assert.Equal(t, "unrelated", readFile(t, filepath.Join(targetDir, "other.txt")))
+
+	stage.cleanup()
+	assert.NoDirExists(t, stage.dir)
+}
+
+func TestStagingCommitRollsBackOnFailure(t *testing.T) {
+	targetDir := t.TempDir()
+	writeFile(t, filepath.Join(targetDir, "a.pdf"), "old a")
+
+	stage, err := newStaging(targetDir, zerolog.Nop())
+	assert.NoError(t, err)
+	defer stage.cleanup()
+
+	writeFile(t, filepath.Join(stage.dir, "a.pdf"), "new a")
+	writeFile(t, filepath.Join(stage.dir, "b.pdf"), "new b")
+
+	// the third file is missing, so moving it fails after the first two have been moved
+	err = stage.commit([]string{
+		filepath.Join(stage.dir, "a.pdf"),
+		filepath.Join(stage.dir, "b.pdf"),
+		filepath.Join(stage.dir, "missing.pdf"),
+	})
+	assert.Error(t, err)
+
+	assert.Equal(t, "old a", readFile(t, filepath.Join(targetDir, "a.pdf")))
+	assert.NoFileExists(t, filepath.Join(targetDir, "b.pdf"))
+}
+
+func TestStagingCommitRemovesCreatedFoldersOnFailure(t *testing.T) {
+	targetDir := t.TempDir()
+
+	stage, err := newStaging(targetDir, zerolog.Nop())
+	assert.NoError(t, err)
+	defer stage.cleanup()
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(stage.dir, "part1", "exercises"), os.ModePerm))
+	writeFile(t, filepath.Join(stage.dir, "part1", "exercises", "a.pdf"), "new a")
+
+	err = stage.commit([]string{
+		filepath.Join(stage.dir, "part1", "exercises", "a.pdf"),
+		filepath.Join(stage.dir, "missing.pdf"),
+	})
+	assert.Error(t, err)
+
+	entries, err := os.ReadDir(targetDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,196 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"os"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"pdfminion/internal/util"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// entries are rendered with a monospaced font, so that the dot leaders line up.
+	// A font file replaces both fonts, the dot leaders line up only if it is monospaced.
+	tocTitleFont          = "Helvetica-Bold"
+	tocEntriesFont        = "Courier"
+	tocTitleDescription   = "points:20, scale:1 abs, rot:0, pos:tl, off:60 -60, align:l, color: 0 0 0"
+	tocEntriesDescription = "points:11, scale:1 abs, rot:0, pos:tl, off:60 -110, align:l, color: 0 0 0"
+
+	tocLinesPerPage = 45
+	tocLineWidth    = 68
+)
+
+var (
+	// leading chapter numbers like "01_" or "2 - " are not part of the title
+	leadingNumberPattern = regexp.MustCompile(`^[0-9]+[\s._-]*`)
+	wordSeparatorPattern = regexp.MustCompile(`[\s_-]+`)
+)
+
+// chapterTitleFromFilename derives a human-readable title from a file name,
+// e.g. "03_error-handling.pdf" becomes "error handling"
+func chapterTitleFromFilename(fileName string) string {
+	base := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+
+	title := leadingNumberPattern.ReplaceAllString(base, "")
+	title = strings.TrimSpace(wordSeparatorPattern.ReplaceAllString(title, " "))
+
+	if title == "" {
+		return base
+	}
+	return title
+}
+
+// CreateTableOfContents writes a standalone PDF to the output directory,
+// listing chapter number, chapter title and starting page of every processed file.
+// It has to be called after AddPageNumbersToAllFiles, as the page ranges are determined there.
+// It returns the path of the created file.
+func (p *Processor) CreateTableOfContents(ctx context.Context, nrOfValidPDFs int, pdfFiles []SingleFileToProcess) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// front and back matter have no chapter number and are not listed
+	entries := make([]string, 0, nrOfValidPDFs)
+	for i := 0; i < nrOfValidPDFs; i++ {
+		if !pdfFiles[i].isMatter() {
+			entries = append(entries, p.tocEntry(pdfFiles[i]))
+		}
+	}
+
+	pages := tocPages(entries)
+	pageCount := p.tocPageCount(len(entries))
+
+	blankPDF, err := createBlankPDF(pageCount)
+	if err != nil {
+		return "", fmt.Errorf("error creating table of contents: %w", err)
+	}
+
+	wmcs, err := p.tocWatermarks(pages)
+	if err != nil {
+		return "", fmt.Errorf("error creating table of contents: %w", err)
+	}
+
+	tocFile := filepath.Join(p.outputDir, domain.DefaultTOCFileName)
+	out, err := os.Create(tocFile)
+	if err != nil {
+		return "", fmt.Errorf("error creating file %s: %w", tocFile, err)
+	}
+	defer out.Close()
+
+	if err := api.AddWatermarksSliceMap(bytes.NewReader(blankPDF), out, wmcs, p.relaxedConf); err != nil {
+		return "", fmt.Errorf("error writing table of contents %s: %w", tocFile, err)
+	}
+
+	p.logger.Debug().Str("file", tocFile).Int("pages", pageCount).Msg("Table of contents created")
+	if p.config.Verbose {
+		fmt.Fprintf(p.out, "Table of contents created (%d pages)\n", pageCount)
+	}
+	return tocFile, nil
+}
+
+// tocPageCount returns the page count of a table of contents with nrOfEntries entries, including blank pages.
+// It is known before the chapters are processed, so PlanChapters can account for it.
+func (p *Processor) tocPageCount(nrOfEntries int) int {
+	pageCount := (nrOfEntries + tocLinesPerPage - 1) / tocLinesPerPage
+	if pageCount == 0 {
+		pageCount = 1
+	}
+	// keep following chapters on right-hand pages when merging
+	switch {
+	case !p.config.Evenify || p.config.EvenifyPolicy == domain.EvenifyPolicyWarn:
+	case p.config.EvenifyPolicy == domain.EvenifyPolicyMultipleOf4:
+		pageCount += p.blankPagesToAppend(pageCount)
+	case !util.IsEven(pageCount):
+		pageCount++
+	}
+	return pageCount
+}
+
+// tocEntry renders a single line like "Chapter 3  Error handling ....... 17"
+func (p *Processor) tocEntry(file SingleFileToProcess) string {
+	chapter := p.config.ChapterPrefix + " " + p.config.FormatChapterNr(file.ChapterNr)
+	// the chapter starts after the blank pages inserted before it
+	page := p.config.FormatPageNr(file.ChapterNr, 1+file.BlankPagesBefore, file.FirstPageNr+file.BlankPagesBefore)
+
+	// at least one blank plus three dots between title and page number
+	maxTitleLength := tocLineWidth - utf8.RuneCountInString(chapter) - utf8.RuneCountInString(page) - 7
+	title := []rune(file.ChapterTitle)
+	if len(title) > maxTitleLength && maxTitleLength > 3 {
+		title = append(title[:maxTitleLength-3], []rune("...")...)
+	}
+
+	left := chapter + "  " + string(title) + " "
+	dots := tocLineWidth - utf8.RuneCountInString(left) - utf8.RuneCountInString(page) - 1
+	if dots < 3 {
+		dots = 3
+	}
+	return left + strings.Repeat(".", dots) + " " + page
+}
+
+// tocPages splits the entries into chunks fitting on a single page
+func tocPages(entries []string) [][]string {
+	pages := make([][]string, 0)
+	for start := 0; start < len(entries); start += tocLinesPerPage {
+		end := start + tocLinesPerPage
+		if end > len(entries) {
+			end = len(entries)
+		}
+		pages = append(pages, entries[start:end])
+	}
+	if len(pages) == 0 {
+		pages = append(pages, []string{})
+	}
+	return pages
+}
+
+// tocWatermarks creates the title (first page only) and the entries for every page of the table of contents
+func (p *Processor) tocWatermarks(pages [][]string) (map[int][]*model.Watermark, error) {
+	wmcs := make(map[int][]*model.Watermark)
+
+	title, err := api.TextWatermark(p.config.TOCTitle, "font:"+p.stampFont(tocTitleFont)+", "+tocTitleDescription, true, false, types.POINTS)
+	if err != nil {
+		return nil, err
+	}
+	wmcs[1] = []*model.Watermark{title}
+
+	for i, lines := range pages {
+		if len(lines) == 0 {
+			continue
+		}
+		wm, err := api.TextWatermark(strings.Join(lines, "\n"), "font:"+p.stampFont(tocEntriesFont)+", "+tocEntriesDescription, true, false, types.POINTS)
+		if err != nil {
+			return nil, err
+		}
+		wmcs[i+1] = append(wmcs[i+1], wm)
+	}
+	return wmcs, nil
+}
+
+// createBlankPDF creates an A4 document with the given number of empty pages
+func createBlankPDF(pageCount int) ([]byte, error) {
+	pages := make(map[string]interface{}, pageCount)
+	for i := 1; i <= pageCount; i++ {
+		pages[strconv.Itoa(i)] = map[string]interface{}{"content": map[string]interface{}{}}
+	}
+
+	layout, err := json.Marshal(map[string]interface{}{"paper": "A4P", "pages": pages})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := api.Create(nil, bytes.NewReader(layout), &buf, model.NewDefaultConfiguration()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
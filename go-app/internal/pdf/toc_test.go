@@ -0,0 +1,69 @@
+package pdf
+
+import (
+	"context"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"pdfminion/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestChapterTitleFromFilename(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"03_error-handling.pdf", "error handling"},
+		{"some/dir/2 - basics.pdf", "basics"},
+		{"Intro.PDF", "Intro"},
+		{"01.pdf", "01"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, chapterTitleFromFilename(tt.input), "input %q", tt.input)
+	}
+}
+
+func TestTOCEntryHasFixedWidth(t *testing.T) {
+	p := NewProcessor(domain.NewDefaultEnglishConfig())
+
+	short := p.tocEntry(SingleFileToProcess{ChapterNr: 1, ChapterTitle: "Intro", FirstPageNr: 1})
+	long := p.tocEntry(SingleFileToProcess{ChapterNr: 12, ChapterTitle: "a very long title that does not fit into a single line of the toc", FirstPageNr: 142})
+
+	assert.Equal(t, tocLineWidth, len(short))
+	assert.Equal(t, tocLineWidth, len(long))
+	assert.Contains(t, short, "Chapter 1  Intro ...")
+}
+
+func TestTOCEntryUsesNumberStyles(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.ChapterNumberStyle = domain.NumberStyleUpperRoman
+	cfg.PageNumbering = domain.PageNumberingChapter
+	p := NewProcessor(cfg)
+
+	entry := p.tocEntry(SingleFileToProcess{ChapterNr: 4, ChapterTitle: "Basics", FirstPageNr: 17})
+	assert.Equal(t, tocLineWidth, len(entry))
+	assert.Contains(t, entry, "Chapter IV  Basics ...")
+	assert.True(t, strings.HasSuffix(entry, " IV.1"), entry)
+}
+
+func TestTOCIsPrependedWhenMerging(t *testing.T) {
+	cfg := domain.NewDefaultEnglishConfig()
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Merge = true
+	cfg.TOC = true
+
+	assert.NoError(t, ProcessPDFs(context.Background(), &cfg))
+
+	// single toc page evenified to 2 pages
+	tocPageCount, err := api.PageCountFile(filepath.Join(cfg.TargetDir, domain.DefaultTOCFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tocPageCount)
+
+	pageCount, err := api.PageCountFile(filepath.Join(cfg.TargetDir, cfg.MergeFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, 8, pageCount)
+}
@@ -0,0 +1,61 @@
+package pdf
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// jobs returns the number of workers used for nrOfFiles files
+func (p *Processor) jobs(nrOfFiles int) int {
+	jobs := p.config.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs > nrOfFiles {
+		jobs = nrOfFiles
+	}
+	return jobs
+}
+
+// forEachFile calls fn for the files 0..nrOfFiles-1 using a bounded pool of workers.
+// fn must only modify data belonging to file i.
+// After the first error, or if ctx is cancelled, no further files are started.
+// The error of the first file in chapter order is returned, so errors are as deterministic as the output.
+func (p *Processor) forEachFile(ctx context.Context, nrOfFiles int, fn func(i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, nrOfFiles)
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < p.jobs(nrOfFiles); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				if errs[i] = fn(i); errs[i] != nil {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < nrOfFiles; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Package minion exposes the PDFminion numbering pipeline as a Go library,
+// so that other tools can evenify, number, label and merge PDF handouts
+// without invoking the command line interface.
+package minion
+
+import (
+	"context"
+	"github.com/rs/zerolog"
+	"golang.org/x/text/language"
+	"io"
+	"pdfminion/internal/domain"
+	"pdfminion/internal/pdf"
+)
+
+// Config is the complete processing configuration, identical to the one used by the CLI
+type Config = domain.MinionConfig
+
+// Result describes the outcome of Processor.Run
+type Result = pdf.Result
+
+// FileResult describes a single processed file: its chapter number, page range,
+// the number of blank pages added and the path of the processed file
+type FileResult = pdf.FileResult
+
+// Plan describes how the PDFs would be processed, see Processor.Plan
+type Plan = pdf.Plan
+
+// PlannedChapter describes how a single file would be processed: its chapter number,
+// original page count, blank pages to be added, page range and footer
+type PlannedChapter = pdf.PlannedChapter
+
+// SkippedFile is a candidate file which has not been processed, together with the reason
+type SkippedFile = pdf.SkippedFile
+
+// Errors returned by Processor.Run, to be checked with errors.Is
+var (
+	ErrInvalidConfig   = domain.ErrInvalidConfig
+	ErrNoPDFsFound     = domain.ErrNoPDFsFound
+	ErrTargetNotEmpty  = domain.ErrTargetNotEmpty
+	ErrInvalidPDF      = domain.ErrInvalidPDF
+	ErrWatermarkFailed = domain.ErrWatermarkFailed
+)
+
+// NewDefaultConfig returns the default configuration for lang.
+// SourceDir and TargetDir have to be set before processing.
+func NewDefaultConfig(lang language.Tag) Config {
+	return domain.NewDefaultConfig(lang)
+}
+
+// Processor processes all PDFs of a source directory with its own configuration
+type Processor struct {
+	config Config
+	out    io.Writer
+	logger zerolog.Logger
+}
+
+// NewProcessor creates a Processor working on a copy of cfg,
+// later changes to cfg do not affect the Processor.
+// It prints and logs nothing, unless an output is set with SetOutput or a logger with SetLogger.
+func NewProcessor(cfg Config) *Processor {
+	return &Processor{config: cfg, out: io.Discard, logger: zerolog.Nop()}
+}
+
+// SetOutput sets the writer for the messages the command line interface prints,
+// like progress, written files, warnings and skipped files
+func (p *Processor) SetOutput(out io.Writer) {
+	p.out = out
+}
+
+// SetLogger sets the logger for debug messages and errors, which the command line interface
+// writes to stderr. The global zerolog logger is not used.
+func (p *Processor) SetLogger(logger zerolog.Logger) {
+	p.logger = logger
+}
+
+// Run validates the configuration and processes all PDFs.
+// Validation errors wrap ErrInvalidConfig or ErrTargetNotEmpty.
+func (p *Processor) Run(ctx context.Context) (*Result, error) {
+	cfg := p.config
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	processor := pdf.NewProcessor(cfg)
+	processor.SetOutput(p.out)
+	processor.SetLogger(p.logger)
+	return processor.Run(ctx)
+}
+
+// Plan validates the configuration and determines chapter numbers, page ranges
+// and footers of all PDFs like Run, but nothing is written.
+func (p *Processor) Plan(ctx context.Context) (*Plan, error) {
+	cfg := p.config
+	cfg.DryRun = true
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	processor := pdf.NewProcessor(cfg)
+	processor.SetOutput(p.out)
+	processor.SetLogger(p.logger)
+	return processor.Plan(ctx)
+}
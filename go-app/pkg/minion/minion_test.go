@@ -0,0 +1,133 @@
+package minion_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+	"os"
+	"path/filepath"
+	"pdfminion/pkg/minion"
+	"strings"
+	"testing"
+)
+
+const sampleDir = "../../sample-files-for-testing/"
+
+func TestRunReturnsPerFileResult(t *testing.T) {
+	cfg := minion.NewDefaultConfig(language.English)
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Merge = true
+
+	result, err := minion.NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 6, result.TotalPageCount)
+	assert.Equal(t, filepath.Join(cfg.TargetDir, cfg.MergeFileName), result.MergedFile)
+	assert.Empty(t, result.TOCFile)
+
+	fileSize := func(fileName string) int64 {
+		info, err := os.Stat(filepath.Join(cfg.SourceDir, fileName))
+		assert.NoError(t, err)
+		return info.Size()
+	}
+
+	assert.Equal(t, []minion.FileResult{
+		{
+			SourcePath:      filepath.Join(cfg.SourceDir, "sample-A4-portrait-1pg.pdf"),
+			OutputPath:      filepath.Join(cfg.TargetDir, "sample-A4-portrait-1pg.pdf"),
+			InputByteCount:  fileSize("sample-A4-portrait-1pg.pdf"),
+			ChapterNr:       1,
+			ChapterTitle:    "sample A4 portrait 1pg",
+			FirstPageNr:     1,
+			LastPageNr:      2,
+			BlankPagesAdded: 1,
+		},
+		{
+			SourcePath:      filepath.Join(cfg.SourceDir, "sample-A4-portrait-3pgs.pdf"),
+			OutputPath:      filepath.Join(cfg.TargetDir, "sample-A4-portrait-3pgs.pdf"),
+			InputByteCount:  fileSize("sample-A4-portrait-3pgs.pdf"),
+			ChapterNr:       2,
+			ChapterTitle:    "sample A4 portrait 3pgs",
+			FirstPageNr:     3,
+			LastPageNr:      6,
+			BlankPagesAdded: 1,
+		},
+	}, result.Files)
+}
+
+func TestRunValidatesConfig(t *testing.T) {
+	cfg := minion.NewDefaultConfig(language.English)
+	cfg.SourceDir = sampleDir + "does-not-exist"
+	cfg.TargetDir = t.TempDir()
+
+	_, err := minion.NewProcessor(cfg).Run(context.Background())
+	assert.True(t, errors.Is(err, minion.ErrInvalidConfig))
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	cfg := minion.NewDefaultConfig(language.English)
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := minion.NewProcessor(cfg).Run(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestPlanDoesNotCreateTarget(t *testing.T) {
+	cfg := minion.NewDefaultConfig(language.English)
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = filepath.Join(t.TempDir(), "target")
+
+	plan, err := minion.NewProcessor(cfg).Plan(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, plan.Chapters, 2)
+	assert.Equal(t, "Chapter 2 - Page 3 of 6", plan.Chapters[1].Footer)
+	assert.NoDirExists(t, cfg.TargetDir)
+}
+
+func TestRunPrintsOnlyToOutput(t *testing.T) {
+	cfg := minion.NewDefaultConfig(language.English)
+	cfg.SourceDir = sampleDir + "TwoPDFs"
+	cfg.TargetDir = t.TempDir()
+	cfg.Merge = true
+
+	// neither printed output nor log messages go to the console,
+	// the global zerolog logger has been created with the original stderr, so it is replaced, too
+	stdout, stderr, logger := os.Stdout, os.Stderr, log.Logger
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	os.Stdout, os.Stderr = w, w
+	log.Logger = zerolog.New(os.Stderr)
+	defer func() {
+		os.Stdout, os.Stderr, log.Logger = stdout, stderr, logger
+	}()
+
+	_, err = minion.NewProcessor(cfg).Run(context.Background())
+	assert.NoError(t, err)
+
+	var out, logged bytes.Buffer
+	cfg.TargetDir = t.TempDir()
+	p := minion.NewProcessor(cfg)
+	p.SetOutput(&out)
+	p.SetLogger(zerolog.New(&logged))
+	_, err = p.Run(context.Background())
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.Close())
+	os.Stdout, os.Stderr = stdout, stderr
+	var printed bytes.Buffer
+	_, err = printed.ReadFrom(r)
+	assert.NoError(t, err)
+	assert.Empty(t, printed.String())
+	assert.True(t, strings.HasPrefix(out.String(), "Merged 2 files"), out.String())
+	assert.Contains(t, out.String(), "Written: "+filepath.Join(cfg.TargetDir, "sample-A4-portrait-1pg.pdf"))
+	assert.Contains(t, logged.String(), "Starting PDF processing")
+}